@@ -0,0 +1,100 @@
+package steam
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+)
+
+// the largest response body the client reads by default. A full page of 100
+// matches from GetMatchHistoryBySequenceNum is a few megabytes.
+const DefaultMaxResponseSize = 32 << 20
+
+// the number of unread bytes drained from a response before closing it, so
+// that the underlying connection can be reused. Bodies with more left over
+// than this are simply closed.
+const drainLimit = 64 << 10
+
+// drains and closes a response body
+func closeBody(res *http.Response) {
+	io.Copy(io.Discard, io.LimitReader(res.Body, drainLimit))
+	res.Body.Close()
+}
+
+// reads and closes a response body, enforcing the client's max response size.
+// Every response the client decodes is read through here.
+func (c *Client) read(ctx context.Context, ep endpoint, res *http.Response) ([]byte, error) {
+	defer closeBody(res)
+	body, err := io.ReadAll(io.LimitReader(res.Body, c.maxResponse+1))
+	if err != nil {
+		if cerr := ctx.Err(); cerr != nil {
+			return nil, ep.errorf(cerr, "request canceled")
+		}
+		e := ep.errorf(err, "unable to read response")
+		e.StatusCode = res.StatusCode
+		e.Retryable = true
+		if errors.Is(err, io.ErrUnexpectedEOF) {
+			// the connection closed before Content-Length bytes arrived
+			e.kind = ErrTruncated
+		}
+		return nil, e
+	}
+	if int64(len(body)) > c.maxResponse {
+		e := ep.errorf(nil, "response exceeds %d bytes", c.maxResponse)
+		e.StatusCode = res.StatusCode
+		e.kind = ErrResponseTooLarge
+		return nil, e
+	}
+	return body, nil
+}
+
+// decodes a response body read by read
+func decode(ep endpoint, res *http.Response, body []byte, dest interface{}) error {
+	err := json.Unmarshal(body, dest)
+	if err == nil {
+		return nil
+	}
+	e := ep.errorf(err, "unable to parse response")
+	e.StatusCode = res.StatusCode
+	// a syntax error means a truncated or garbled body rather than a change
+	// in the response schema; trying again may help
+	var serr *json.SyntaxError
+	if errors.As(err, &serr) {
+		e.Retryable = true
+		if serr.Offset >= int64(len(body)) {
+			e.kind = ErrTruncated
+		}
+	}
+	return e
+}
+
+// a response body that fails once more than n bytes have been read from it,
+// used for the raw responses handed out by Get
+type limitedBody struct {
+	io.ReadCloser
+	ep endpoint
+	n  int64
+}
+
+func (l *limitedBody) Read(p []byte) (int, error) {
+	if l.n < 0 {
+		return 0, l.err()
+	}
+	if int64(len(p)) > l.n+1 {
+		p = p[:l.n+1]
+	}
+	n, err := l.ReadCloser.Read(p)
+	l.n -= int64(n)
+	if l.n < 0 {
+		return n - 1, l.err()
+	}
+	return n, err
+}
+
+func (l *limitedBody) err() error {
+	e := l.ep.errorf(nil, "response exceeds size limit")
+	e.kind = ErrResponseTooLarge
+	return e
+}
@@ -0,0 +1,96 @@
+package steam
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// serves the same body to every request. A declared length longer than the
+// body makes the server drop the connection part way through.
+func bodyServer(body string, length int) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Length", fmt.Sprint(length))
+		io.WriteString(w, body)
+	}))
+}
+
+func TestResponseSizeLimit(t *testing.T) {
+	body := `{"response":{"players":[]}}`
+	tests := []struct {
+		max  int64
+		kind error
+	}{
+		{int64(len(body)), nil},
+		{int64(len(body)) - 1, ErrResponseTooLarge},
+	}
+	for _, test := range tests {
+		srv := bodyServer(body, len(body))
+		c := NewClient("key", WithBaseURL(srv.URL), WithMaxResponseSize(test.max))
+		_, err := c.GetPlayerSummaries(1)
+		srv.Close()
+		if !errors.Is(err, test.kind) || (test.kind == nil) != (err == nil) {
+			t.Errorf("max %d: got error %v, want %v", test.max, err, test.kind)
+		}
+	}
+}
+
+func TestTruncatedResponse(t *testing.T) {
+	full := `{"response":{"players":[{"steamid":"76561197960287930"}]}}`
+	tests := []struct {
+		name   string
+		body   string
+		length int
+	}{
+		// the connection closes before Content-Length bytes arrive
+		{"short read", full[:20], len(full)},
+		// the body is complete as far as http goes, but not as json
+		{"cut json", full[:20], 20},
+	}
+	for _, test := range tests {
+		srv := bodyServer(test.body, test.length)
+		c := NewClient("key", WithBaseURL(srv.URL), WithRetryPolicy(RetryPolicy{MaxAttempts: 1}))
+		_, err := c.GetPlayerSummaries(1)
+		srv.Close()
+		if !errors.Is(err, ErrTruncated) {
+			t.Errorf("%s: got error %v, want ErrTruncated", test.name, err)
+			continue
+		}
+		var cerr ClientError
+		if !errors.As(err, &cerr) || !cerr.Retryable {
+			t.Errorf("%s: truncated response not retryable: %v", test.name, err)
+		}
+	}
+}
+
+func TestGetSizeLimit(t *testing.T) {
+	body := strings.Repeat("x", 100)
+	srv := bodyServer(body, len(body))
+	defer srv.Close()
+
+	for _, max := range []int64{100, 99, 1} {
+		c := NewClient("key", WithBaseURL(srv.URL), WithMaxResponseSize(max))
+		res, err := c.Get("ISteamUser", "GetFriendList", "v0001")
+		if err != nil {
+			t.Fatal(err)
+		}
+		got, err := io.ReadAll(res.Body)
+		res.Body.Close()
+		if max >= int64(len(body)) {
+			if err != nil || string(got) != body {
+				t.Errorf("max %d: read %d bytes, error %v", max, len(got), err)
+			}
+			continue
+		}
+		if !errors.Is(err, ErrResponseTooLarge) {
+			t.Errorf("max %d: got error %v, want ErrResponseTooLarge", max, err)
+		}
+		if int64(len(got)) != max {
+			t.Errorf("max %d: read %d bytes before failing", max, len(got))
+		}
+	}
+}
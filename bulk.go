@@ -0,0 +1,165 @@
+package steam
+
+import (
+	"context"
+	"sync"
+)
+
+// the number of requests a bulk lookup keeps in flight. Requests are still
+// paced by the client's limiter.
+const bulkConcurrency = 4
+
+// the most ids GetPlayerSummaries accepts in one call
+const maxSummaryIds = 100
+
+func (c *Client) GetPlayerSummariesBulk(steamids []SteamID) ([]PlayerSummary, []SteamID, error) {
+	return c.GetPlayerSummariesBulkContext(context.Background(), steamids)
+}
+
+// looks up the summaries of any number of players. Ids are deduplicated and
+// fetched in chunks of 100, several chunks at a time. The summaries are
+// returned in the order their ids first appear in steamids, followed by the
+// ids steam returned nothing for, typically because the account is private
+// or deleted. If any chunk fails the whole lookup fails.
+func (c *Client) GetPlayerSummariesBulkContext(ctx context.Context, steamids []SteamID) ([]PlayerSummary, []SteamID, error) {
+	seen := make(map[SteamID]bool, len(steamids))
+	ids := make([]SteamID, 0, len(steamids))
+	for _, id := range steamids {
+		if !seen[id] {
+			seen[id] = true
+			ids = append(ids, id)
+		}
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	var (
+		mu       sync.Mutex
+		found    = make(map[SteamID]PlayerSummary, len(ids))
+		firstErr error
+		wg       sync.WaitGroup
+		sem      = make(chan struct{}, bulkConcurrency)
+	)
+	for start := 0; start < len(ids); start += maxSummaryIds {
+		end := start + maxSummaryIds
+		if end > len(ids) {
+			end = len(ids)
+		}
+		chunk := ids[start:end]
+		wg.Add(1)
+		sem <- struct{}{}
+		go func() {
+			defer func() { <-sem; wg.Done() }()
+			players, err := c.GetPlayerSummariesContext(ctx, chunk...)
+			mu.Lock()
+			defer mu.Unlock()
+			if err != nil {
+				if firstErr == nil {
+					firstErr = err
+					cancel()
+				}
+				return
+			}
+			for _, p := range players {
+				found[p.SteamId] = p
+			}
+		}()
+	}
+	wg.Wait()
+	if firstErr != nil {
+		return nil, nil, firstErr
+	}
+
+	players := make([]PlayerSummary, 0, len(found))
+	var missing []SteamID
+	for _, id := range ids {
+		if p, ok := found[id]; ok {
+			players = append(players, p)
+		} else {
+			missing = append(missing, id)
+		}
+	}
+	return players, missing, nil
+}
+
+// the outcome of fetching a single match in a bulk fetch
+type DotaMatchDetailsResult struct {
+	Id      uint64
+	Details *DotaMatchDetails
+	Err     error
+}
+
+// a DotaMatchStore reports which matches a caller already has, so that a bulk
+// fetch can skip them
+type DotaMatchStore interface {
+	HasMatch(id uint64) bool
+}
+
+type DotaMatchDetailsBulkOptions struct {
+	// the number of matches fetched at once. Defaults to 4. Requests are
+	// still paced by the client's limiter.
+	Workers int
+
+	// if non-nil, matches the store already has are skipped
+	Store DotaMatchStore
+
+	// skip matches whose details are already in the client's cache
+	SkipCached bool
+}
+
+func (c *Client) DotaMatchDetailsBulk(ids []uint64, opts DotaMatchDetailsBulkOptions) <-chan DotaMatchDetailsResult {
+	return c.DotaMatchDetailsBulkContext(context.Background(), ids, opts)
+}
+
+// fetches the details of many matches with a pool of workers, streaming the
+// results on the returned channel as they complete. Every id that isn't
+// skipped produces exactly one result, carrying either the details or the
+// error for that match; a failed match doesn't stop the others. Duplicate ids
+// are fetched once. The channel is closed when every match has been fetched
+// or ctx is done.
+func (c *Client) DotaMatchDetailsBulkContext(ctx context.Context, ids []uint64, opts DotaMatchDetailsBulkOptions) <-chan DotaMatchDetailsResult {
+	workers := opts.Workers
+	if workers <= 0 {
+		workers = bulkConcurrency
+	}
+
+	todo := make(chan uint64)
+	results := make(chan DotaMatchDetailsResult)
+	go func() {
+		defer close(todo)
+		seen := make(map[uint64]bool, len(ids))
+		for _, id := range ids {
+			if seen[id] || (opts.Store != nil && opts.Store.HasMatch(id)) || (opts.SkipCached && c.isCached(epMatchDetails, matchDetailsParams(id))) {
+				continue
+			}
+			seen[id] = true
+			select {
+			case todo <- id:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	var wg sync.WaitGroup
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for id := range todo {
+				details, err := c.DotaMatchDetailsContext(ctx, id)
+				select {
+				case results <- DotaMatchDetailsResult{Id: id, Details: details, Err: err}:
+				case <-ctx.Done():
+					return
+				}
+			}
+		}()
+	}
+	go func() {
+		wg.Wait()
+		close(results)
+	}()
+	return results
+}
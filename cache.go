@@ -0,0 +1,164 @@
+package steam
+
+import (
+	"container/list"
+	"crypto/sha256"
+	"encoding/hex"
+	"os"
+	"path/filepath"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// a Cache stores raw api response bodies. Implementations must be safe for
+// concurrent use.
+type Cache interface {
+	// retrieves an unexpired value
+	Get(key string) ([]byte, bool)
+
+	// stores a value for the given duration. A ttl of NoExpiry stores the
+	// value indefinitely.
+	Set(key string, value []byte, ttl time.Duration)
+}
+
+// a ttl for responses that never change
+const NoExpiry time.Duration = -1
+
+// a CachePolicy maps endpoints, written as "Interface/Method", to the length
+// of time their responses are cached. Endpoints not in the policy aren't
+// cached.
+type CachePolicy map[string]time.Duration
+
+var DefaultCachePolicy = CachePolicy{
+	// details are only available for matches that are over, at which point
+	// they can't change
+	"IDOTA2Match_570/GetMatchDetails":      NoExpiry,
+	"ISteamWebAPIUtil/GetSupportedAPIList": time.Hour,
+	"ISteamUser/GetPlayerSummaries":        time.Minute,
+	"IEconDOTA2_570/GetHeroes":             24 * time.Hour,
+	"IEconDOTA2_570/GetGameItems":          24 * time.Hour,
+	"datafeed/abilitylist":                 24 * time.Hour,
+}
+
+func (p CachePolicy) ttl(ep endpoint) time.Duration {
+	return p[ep.iface+"/"+ep.method]
+}
+
+// cache hit and miss counts for a Client
+type CacheStats struct {
+	Hits   uint64
+	Misses uint64
+}
+
+// a MemoryCache is an in-memory Cache that evicts the least recently used
+// entry once it holds its maximum number of entries
+type MemoryCache struct {
+	mu      sync.Mutex
+	max     int
+	order   *list.List
+	entries map[string]*list.Element
+}
+
+type memoryEntry struct {
+	key     string
+	value   []byte
+	expires time.Time
+}
+
+func NewMemoryCache(maxEntries int) *MemoryCache {
+	return &MemoryCache{
+		max:     maxEntries,
+		order:   list.New(),
+		entries: make(map[string]*list.Element),
+	}
+}
+
+func (m *MemoryCache) Get(key string) ([]byte, bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	el, ok := m.entries[key]
+	if !ok {
+		return nil, false
+	}
+	e := el.Value.(*memoryEntry)
+	if !e.expires.IsZero() && time.Now().After(e.expires) {
+		m.order.Remove(el)
+		delete(m.entries, key)
+		return nil, false
+	}
+	m.order.MoveToFront(el)
+	return e.value, true
+}
+
+func (m *MemoryCache) Set(key string, value []byte, ttl time.Duration) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	var expires time.Time
+	if ttl > 0 {
+		expires = time.Now().Add(ttl)
+	}
+	if el, ok := m.entries[key]; ok {
+		el.Value = &memoryEntry{key: key, value: value, expires: expires}
+		m.order.MoveToFront(el)
+		return
+	}
+	m.entries[key] = m.order.PushFront(&memoryEntry{key: key, value: value, expires: expires})
+	for m.max > 0 && m.order.Len() > m.max {
+		el := m.order.Back()
+		m.order.Remove(el)
+		delete(m.entries, el.Value.(*memoryEntry).key)
+	}
+}
+
+// a DirCache is a Cache that keeps one file per entry in a directory on disk.
+// Each file starts with a line holding the entry's expiry as a unix
+// timestamp, or 0 for entries that never expire.
+type DirCache struct {
+	dir string
+}
+
+// creates a DirCache, creating its directory if it doesn't exist
+func NewDirCache(dir string) (*DirCache, error) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, errorf(err, "unable to create cache dir")
+	}
+	return &DirCache{dir: dir}, nil
+}
+
+func (d *DirCache) path(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return filepath.Join(d.dir, hex.EncodeToString(sum[:]))
+}
+
+func (d *DirCache) Get(key string) ([]byte, bool) {
+	b, err := os.ReadFile(d.path(key))
+	if err != nil {
+		return nil, false
+	}
+	for i := range b {
+		if b[i] != '\n' {
+			continue
+		}
+		expires, err := strconv.ParseInt(string(b[:i]), 10, 64)
+		if err != nil {
+			return nil, false
+		}
+		if expires != 0 && time.Now().Unix() > expires {
+			os.Remove(d.path(key))
+			return nil, false
+		}
+		return b[i+1:], true
+	}
+	return nil, false
+}
+
+func (d *DirCache) Set(key string, value []byte, ttl time.Duration) {
+	var expires int64
+	if ttl > 0 {
+		expires = time.Now().Add(ttl).Unix()
+	}
+	b := append([]byte(strconv.FormatInt(expires, 10)+"\n"), value...)
+	// writing atomically keeps concurrent readers from seeing a partial entry
+	writeFileAtomic(d.path(key), b, 0600)
+}
@@ -0,0 +1,163 @@
+package steam
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestMemoryCacheEviction(t *testing.T) {
+	m := NewMemoryCache(2)
+	m.Set("a", []byte("a"), NoExpiry)
+	m.Set("b", []byte("b"), NoExpiry)
+	// reading a makes b the least recently used
+	if _, ok := m.Get("a"); !ok {
+		t.Fatal("a missing")
+	}
+	m.Set("c", []byte("c"), NoExpiry)
+	if _, ok := m.Get("b"); ok {
+		t.Error("b survived eviction")
+	}
+	for _, key := range []string{"a", "c"} {
+		if v, ok := m.Get(key); !ok || string(v) != key {
+			t.Errorf("%s = %q, %t", key, v, ok)
+		}
+	}
+
+	// replacing an entry doesn't evict anything
+	m.Set("a", []byte("A"), NoExpiry)
+	if v, _ := m.Get("a"); string(v) != "A" {
+		t.Errorf("a = %q after replacing it", v)
+	}
+	if _, ok := m.Get("c"); !ok {
+		t.Error("replacing a evicted c")
+	}
+}
+
+func TestMemoryCacheExpiry(t *testing.T) {
+	m := NewMemoryCache(0)
+	m.Set("short", []byte("x"), 10*time.Millisecond)
+	m.Set("forever", []byte("x"), NoExpiry)
+	if _, ok := m.Get("short"); !ok {
+		t.Fatal("entry expired early")
+	}
+	time.Sleep(20 * time.Millisecond)
+	if _, ok := m.Get("short"); ok {
+		t.Error("entry outlived its ttl")
+	}
+	if _, ok := m.Get("forever"); !ok {
+		t.Error("NoExpiry entry expired")
+	}
+}
+
+func TestDirCache(t *testing.T) {
+	d, err := NewDirCache(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	d.Set("forever", []byte("body\nwith lines"), NoExpiry)
+	d.Set("hour", []byte("x"), time.Hour)
+	if v, ok := d.Get("forever"); !ok || string(v) != "body\nwith lines" {
+		t.Errorf("forever = %q, %t", v, ok)
+	}
+	if _, ok := d.Get("hour"); !ok {
+		t.Error("entry expired early")
+	}
+	if _, ok := d.Get("missing"); ok {
+		t.Error("hit on a missing entry")
+	}
+
+	// an entry whose expiry has passed is a miss and is removed
+	if err := os.WriteFile(d.path("old"), []byte("1\nx"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := d.Get("old"); ok {
+		t.Error("expired entry served")
+	}
+	if _, err := os.Stat(d.path("old")); !os.IsNotExist(err) {
+		t.Error("expired entry not removed")
+	}
+}
+
+func TestClientCache(t *testing.T) {
+	var calls atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		switch r.URL.Path {
+		case "/ISteamUser/GetPlayerSummaries/v0002/":
+			fmt.Fprint(w, `{"response":{"players":[{"steamid":"76561197960287930"}]}}`)
+		case "/ISteamUser/GetFriendList/v1/":
+			fmt.Fprint(w, `{"friendslist":{"friends":[]}}`)
+		}
+	}))
+	defer srv.Close()
+	c := NewClient("k", WithBaseURL(srv.URL), WithCache(NewMemoryCache(10)))
+
+	// summaries are in the default policy
+	for i := 0; i < 2; i++ {
+		players, err := c.GetPlayerSummaries(76561197960287930)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(players) != 1 {
+			t.Fatalf("players: %v", players)
+		}
+	}
+	if calls.Load() != 1 {
+		t.Errorf("made %d requests, want 1", calls.Load())
+	}
+	if stats := c.CacheStats(); stats.Hits != 1 || stats.Misses != 1 {
+		t.Errorf("stats %+v", stats)
+	}
+
+	// friend lists aren't, and don't count towards the stats
+	for i := 0; i < 2; i++ {
+		if _, err := c.GetFriendList(76561197960287930); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if calls.Load() != 3 {
+		t.Errorf("made %d requests, want 3", calls.Load())
+	}
+	if stats := c.CacheStats(); stats.Hits != 1 || stats.Misses != 1 {
+		t.Errorf("stats %+v", stats)
+	}
+}
+
+func TestClientCacheSkipsFailedChecks(t *testing.T) {
+	var calls atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if calls.Add(1) == 1 {
+			fmt.Fprint(w, `{"response":{"success":42,"message":"No match"}}`)
+			return
+		}
+		fmt.Fprint(w, `{"response":{"success":1,"steamid":"76561197960287930"}}`)
+	}))
+	defer srv.Close()
+	c := NewClient("k",
+		WithBaseURL(srv.URL),
+		WithCache(NewMemoryCache(10)),
+		WithCachePolicy(CachePolicy{"ISteamUser/ResolveVanityURL": time.Minute}))
+
+	if _, err := c.ResolveVanityUrl("gaben"); !errors.Is(err, ErrNotFound) {
+		t.Fatalf("first lookup: %v", err)
+	}
+	for i := 0; i < 2; i++ {
+		id, err := c.ResolveVanityUrl("gaben")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if id != 76561197960287930 {
+			t.Errorf("id %d", id)
+		}
+	}
+	// the failed lookup wasn't cached, the successful one was
+	if calls.Load() != 2 {
+		t.Errorf("made %d requests, want 2", calls.Load())
+	}
+}
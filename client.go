@@ -1,158 +1,566 @@
 package steam
 
 import (
+	"bytes"
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
 	"net/http"
+	"net/url"
 	"strconv"
 	"strings"
+	"sync/atomic"
+	"time"
 )
 
 // a steam API client, not tied to any particular game
 type Client struct {
-	key string
+	key       string
+	base      string
+	http      *http.Client
+	transport http.RoundTripper
+	userAgent string
+	timeout   time.Duration
+	limiter   Limiter
+	budget    *DailyBudget
+
+	retryPolicy RetryPolicy
+
+	cache       Cache
+	cachePolicy CachePolicy
+	cacheHits   atomic.Uint64
+	cacheMisses atomic.Uint64
+
+	traceFn     TraceFunc
+	maxResponse int64
+	datafeed    string
+}
+
+func NewClient(key string, opts ...Option) *Client {
+	c := &Client{
+		key:     key,
+		base:    DefaultBaseURL,
+		limiter: NewTokenBucket(DefaultRate, DefaultRateOverrides),
+		budget:  NewDailyBudget(DefaultDailyBudget),
+
+		retryPolicy: DefaultRetryPolicy,
+		cachePolicy: DefaultCachePolicy,
+		maxResponse: DefaultMaxResponseSize,
+		datafeed:    DefaultDotaDatafeedURL,
+	}
+	for _, opt := range opts {
+		opt(c)
+	}
+	if c.http == nil {
+		c.http = &http.Client{}
+	}
+	if c.transport != nil {
+		c.http.Transport = c.transport
+	}
+	if c.timeout > 0 {
+		c.http.Timeout = c.timeout
+	}
+	return c
+}
+
+// the client's daily call budget, or nil if it has none
+func (c *Client) Budget() *DailyBudget {
+	return c.budget
+}
+
+// the client's cache hit and miss counts. Only calls to endpoints covered by
+// the cache policy are counted.
+func (c *Client) CacheStats() CacheStats {
+	return CacheStats{Hits: c.cacheHits.Load(), Misses: c.cacheMisses.Load()}
 }
 
-func NewClient(key string) *Client {
-	return &Client{key: key}
+// identifies a single api method
+type endpoint struct {
+	iface   string
+	method  string
+	version string
+}
+
+var (
+	epFriendList      = endpoint{"ISteamUser", "GetFriendList", "v1"}
+	epResolveVanity   = endpoint{"ISteamUser", "ResolveVanityURL", "v0001"}
+	epPlayerSummaries = endpoint{"ISteamUser", "GetPlayerSummaries", "v0002"}
+	epMatchSequence   = endpoint{"IDOTA2Match_570", "GetMatchHistoryBySequenceNum", "v1"}
+	epMatchHistory    = endpoint{"IDOTA2Match_570", "GetMatchHistory", "v0001"}
+	epMatchDetails    = endpoint{"IDOTA2Match_570", "GetMatchDetails", "v0001"}
+)
+
+// builds the url for a given api method. The api key is always added to the
+// provided params.
+func (c *Client) url(ep endpoint, params url.Values) string {
+	q := url.Values{"key": {c.key}}
+	for k, v := range params {
+		q[k] = v
+	}
+	return fmt.Sprintf("%s/%s/%s/%s/?%s", c.base, ep.iface, ep.method, ep.version, q.Encode())
+}
+
+// performs a GET request against a fully formed api url. All steam web api
+// requests made by the client go through here. Responses with a status other
+// than 200 OK are closed and reported as errors.
+func (c *Client) get(ctx context.Context, ep endpoint, u string) (*http.Response, error) {
+	if err := c.wait(ctx, ep); err != nil {
+		return nil, err
+	}
+	return c.send(ctx, ep, u)
+}
+
+// sends a request and reports it to the trace hook. Requests to servers other
+// than the steam web api, such as the dota2.com data feed, come here directly
+// rather than through get, since they aren't paced by the limiter or counted
+// against the budget.
+func (c *Client) send(ctx context.Context, ep endpoint, u string) (*http.Response, error) {
+	start := time.Now()
+	info := RequestInfo{
+		Interface: ep.iface,
+		Method:    ep.method,
+		Version:   ep.version,
+		URL:       redactURL(u),
+	}
+	res, err := c.do(ctx, ep, u)
+	if res != nil {
+		info.StatusCode = res.StatusCode
+	}
+	info.Err = err
+	info.Duration = time.Since(start)
+	c.trace(info)
+	if err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+// sends a single request. A response with a non-200 status is closed and
+// returned along with its error so that get can trace its status code.
+func (c *Client) do(ctx context.Context, ep endpoint, u string) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, "GET", u, nil)
+	if err != nil {
+		return nil, ep.errorf(redactErr(err), "unable to create request")
+	}
+	if c.userAgent != "" {
+		req.Header.Set("User-Agent", c.userAgent)
+	}
+	res, err := c.http.Do(req)
+	if err != nil {
+		if cerr := ctx.Err(); cerr != nil {
+			return nil, ep.errorf(cerr, "request canceled")
+		}
+		e := ep.errorf(redactErr(err), "request failed")
+		e.Retryable = true
+		return nil, e
+	}
+	if res.StatusCode != http.StatusOK {
+		closeBody(res)
+		return res, ep.statusError(res)
+	}
+	return res, nil
+}
+
+// waits for the client's limiter and takes a call from the daily budget
+func (c *Client) wait(ctx context.Context, ep endpoint) error {
+	if c.budget != nil {
+		if err := c.budget.take(); err != nil {
+			e := ep.errorf(err, "call refused")
+			e.kind = ErrRateLimited
+			return e
+		}
+	}
+	if c.limiter != nil {
+		if err := c.limiter.Wait(ctx, ep.iface); err != nil {
+			if c.budget != nil {
+				c.budget.refund()
+			}
+			return ep.errorf(err, "request canceled")
+		}
+	}
+	return nil
+}
+
+// the key under which an api call's response is cached. It never includes
+// the api key.
+func cacheKey(ep endpoint, params url.Values) string {
+	return fmt.Sprintf("%s/%s/%s?%s", ep.iface, ep.method, ep.version, params.Encode())
+}
+
+// looks up a cached response for an api call, if the call is cacheable. The
+// returned key is empty if the response shouldn't be cached.
+func (c *Client) cached(ep endpoint, params url.Values) (key string, body []byte, ok bool) {
+	if c.cache == nil || c.cachePolicy.ttl(ep) == 0 {
+		return "", nil, false
+	}
+	key = cacheKey(ep, params)
+	if body, ok := c.cache.Get(key); ok {
+		c.cacheHits.Add(1)
+		c.trace(RequestInfo{
+			Interface: ep.iface,
+			Method:    ep.method,
+			Version:   ep.version,
+			Cached:    true,
+		})
+		return key, body, true
+	}
+	c.cacheMisses.Add(1)
+	return key, nil, false
+}
+
+// reports whether the response to an api call is in the cache, without
+// counting towards the cache stats
+func (c *Client) isCached(ep endpoint, params url.Values) bool {
+	if c.cache == nil || c.cachePolicy.ttl(ep) == 0 {
+		return false
+	}
+	_, ok := c.cache.Get(cacheKey(ep, params))
+	return ok
+}
+
+// calls an api method and decodes its json response into dest, retrying
+// according to the client's retry policy. If check is non-nil it is called
+// after decoding to inspect the result; responses that fail the check are
+// never cached.
+func (c *Client) call(ctx context.Context, ep endpoint, params url.Values, dest interface{}, check func() error) error {
+	return c.fetch(ctx, ep, params, c.url(ep, params), c.get, dest, check)
+}
+
+// does the work of call against an arbitrary url, sending each attempt with
+// send. params identify the response in the cache.
+func (c *Client) fetch(ctx context.Context, ep endpoint, params url.Values, u string, send func(context.Context, endpoint, string) (*http.Response, error), dest interface{}, check func() error) error {
+	key, body, ok := c.cached(ep, params)
+	if ok && json.Unmarshal(body, dest) == nil && (check == nil || check() == nil) {
+		return nil
+	}
+	err := c.retry(ctx, ep, func() error {
+		res, err := send(ctx, ep, u)
+		if err != nil {
+			return err
+		}
+		body, err = c.read(ctx, ep, res)
+		if err != nil {
+			return err
+		}
+		return decode(ep, res, body, dest)
+	})
+	if err != nil {
+		return err
+	}
+	if check != nil {
+		if err := check(); err != nil {
+			return err
+		}
+	}
+	if key != "" {
+		c.cache.Set(key, body, c.cachePolicy.ttl(ep))
+	}
+	return nil
 }
 
 func (c *Client) Get(iface, method, version string) (*http.Response, error) {
-	url := fmt.Sprintf("https://api.steampowered.com/%s/%s/%s/?key=%s", iface, method, version, c.key)
-	return http.Get(url)
+	return c.GetContext(context.Background(), iface, method, version)
 }
 
-func (c *Client) GetFriendList(userid uint64) ([]PlayerFriend, error) {
-	url := fmt.Sprintf("https://api.steampowered.com/ISteamUser/GetFriendList/v1/?key=%s&steamid=%d", c.key, userid)
-	res, err := http.Get(url)
+// performs a raw api call. The caller is responsible for closing the response
+// body, reads from which fail once the client's max response size is
+// exceeded. Endpoints covered by the cache policy are served from the cache when
+// possible, in which case the response is synthesized from the cached body.
+func (c *Client) GetContext(ctx context.Context, iface, method, version string) (*http.Response, error) {
+	ep := endpoint{iface, method, version}
+	key, body, ok := c.cached(ep, nil)
+	if ok {
+		return &http.Response{
+			Status:     "200 OK",
+			StatusCode: http.StatusOK,
+			Proto:      "HTTP/1.1",
+			ProtoMajor: 1,
+			ProtoMinor: 1,
+			Header:     http.Header{"Content-Type": {"application/json"}},
+			Body:       io.NopCloser(bytes.NewReader(body)),
+		}, nil
+	}
+	u := c.url(ep, nil)
+	var res *http.Response
+	err := c.retry(ctx, ep, func() error {
+		var err error
+		res, err = c.get(ctx, ep, u)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	if key == "" {
+		res.Body = &limitedBody{ReadCloser: res.Body, ep: ep, n: c.maxResponse}
+		return res, nil
+	}
+	body, err = c.read(ctx, ep, res)
 	if err != nil {
-		return nil, errorf(err, "unable to get friend list")
+		return nil, err
 	}
+	c.cache.Set(key, body, c.cachePolicy.ttl(ep))
+	res.Body = io.NopCloser(bytes.NewReader(body))
+	return res, nil
+}
+
+func (c *Client) GetFriendList(userid SteamID) ([]PlayerFriend, error) {
+	return c.GetFriendListContext(context.Background(), userid)
+}
+
+func (c *Client) GetFriendListContext(ctx context.Context, userid SteamID) ([]PlayerFriend, error) {
+	params := url.Values{"steamid": {userid.String()}}
 	var response struct {
 		V struct {
 			Friends []PlayerFriend `json:"friends"`
 		} `json:"friendslist"`
 	}
-	if err := json.NewDecoder(res.Body).Decode(&response); err != nil {
-		return nil, errorf(err, "unable to parse friends list response")
+	if err := c.call(ctx, epFriendList, params, &response, nil); err != nil {
+		// steam reports a private friends list as 401 Unauthorized
+		var e ClientError
+		if errors.As(err, &e) && e.StatusCode == http.StatusUnauthorized {
+			e.kind = ErrPrivateProfile
+			return nil, e
+		}
+		return nil, err
 	}
 	return response.V.Friends, nil
 }
 
-func (c *Client) ResolveVanityUrl(vanity string) (uint64, error) {
+func (c *Client) ResolveVanityUrl(vanity string) (SteamID, error) {
+	return c.ResolveVanityUrlContext(context.Background(), vanity)
+}
+
+func (c *Client) ResolveVanityUrlContext(ctx context.Context, vanity string) (SteamID, error) {
 	var v struct {
 		V struct {
-			Id      uint64 `json:"steamid,string"`
-			Success int    `json:"success"`
+			Id      SteamID `json:"steamid"`
+			Success int     `json:"success"`
+			Message string  `json:"message"`
 		} `json:"response"`
 	}
-	url := fmt.Sprintf("https://api.steampowered.com/ISteamUser/ResolveVanityURL/v0001/?key=%s&vanityurl=%s", c.key, vanity)
-	res, err := http.Get(url)
+	params := url.Values{"vanityurl": {vanity}}
+	err := c.call(ctx, epResolveVanity, params, &v, func() error {
+		if v.V.Success == 1 {
+			return nil
+		}
+		e := epResolveVanity.resultError(v.V.Success, v.V.Message)
+		if v.V.Success == 42 {
+			// k_EResultNoMatch
+			e.kind = ErrNotFound
+		}
+		return e
+	})
 	if err != nil {
-		return 0, errorf(err, "unable to resolve vanity url")
+		return 0, err
+	}
+	return v.V.Id, nil
+}
+
+func (c *Client) ResolveUser(user string) (SteamID, error) {
+	return c.ResolveUserContext(context.Background(), user)
+}
+
+// resolves any of the ways a user can be identified to their SteamID: a
+// SteamID64, steam2 or steam3 id, a steamcommunity.com/profiles/<id> or
+// steamcommunity.com/id/<vanity> url, or a bare vanity name. Only vanity names
+// require an api call. Input that is entirely numeric is always treated as an
+// id, never as a vanity name.
+func (c *Client) ResolveUserContext(ctx context.Context, user string) (SteamID, error) {
+	user = strings.TrimSpace(user)
+	if user == "" {
+		return 0, errorf(nil, "empty user identifier")
 	}
-	if err := json.NewDecoder(res.Body).Decode(&v); err != nil {
-		return 0, errorf(err, "unable to decode vanity url response")
+	if kind, value, ok := parseProfileURL(user); ok {
+		if kind == "profiles" {
+			return ParseSteamID(value)
+		}
+		return c.ResolveVanityUrlContext(ctx, value)
 	}
-	if v.V.Success != 1 {
-		return 0, errorf(err, "resolving vanity url returned non-1 status")
+	if isDigits(user) {
+		id, err := ParseSteamID(user)
+		if err == nil && id == 0 {
+			err = errorf(nil, "invalid steam id %q", user)
+		}
+		return id, err
 	}
-	return v.V.Id, nil
+	if id, err := ParseSteamID(user); err == nil {
+		return id, nil
+	}
+	return c.ResolveVanityUrlContext(ctx, user)
+}
+
+func isDigits(s string) bool {
+	for _, r := range s {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+	return s != ""
 }
 
-func (c *Client) GetPlayerSummaries(steamids ...uint64) ([]PlayerSummary, error) {
-	if len(steamids) > 100 {
-		return nil, errorf(nil, "GetPlayerSummaries accepts a max of 100 ids, saw %d", len(steamids))
+// splits a steam community profile url into its kind ("id" or "profiles")
+// and the identifier that follows it. The scheme is optional.
+func parseProfileURL(s string) (kind, value string, ok bool) {
+	if i := strings.Index(s, "://"); i >= 0 {
+		s = s[i+3:]
+	}
+	s = strings.TrimPrefix(s, "www.")
+	if !strings.HasPrefix(s, "steamcommunity.com/") {
+		return "", "", false
+	}
+	parts := strings.Split(strings.TrimPrefix(s, "steamcommunity.com/"), "/")
+	if len(parts) < 2 || parts[1] == "" {
+		return "", "", false
+	}
+	switch parts[0] {
+	case "id", "profiles":
+		value, err := url.PathUnescape(parts[1])
+		if err != nil {
+			return "", "", false
+		}
+		return parts[0], value, true
+	}
+	return "", "", false
+}
+
+func (c *Client) GetPlayerSummaries(steamids ...SteamID) ([]PlayerSummary, error) {
+	return c.GetPlayerSummariesContext(context.Background(), steamids...)
+}
+
+func (c *Client) GetPlayerSummariesContext(ctx context.Context, steamids ...SteamID) ([]PlayerSummary, error) {
+	if len(steamids) > maxSummaryIds {
+		return nil, epPlayerSummaries.errorf(nil, "accepts a max of %d ids, saw %d", maxSummaryIds, len(steamids))
 	}
 	ids_s := make([]string, len(steamids))
 	for i := range steamids {
-		ids_s[i] = strconv.FormatUint(steamids[i], 10)
-	}
-	ids := strings.Join(ids_s, ",")
-	url := fmt.Sprintf("https://api.steampowered.com/ISteamUser/GetPlayerSummaries/v0002/?key=%s&steamids=%s", c.key, ids)
-	res, err := http.Get(url)
-	if err != nil {
-		return nil, errorf(err, "unable to call GetPlayerSummaries API")
+		ids_s[i] = steamids[i].String()
 	}
+	params := url.Values{"steamids": {strings.Join(ids_s, ",")}}
 	var response struct {
 		V struct {
 			Players []PlayerSummary `json:"players"`
 		} `json:"response"`
 	}
-	if err := json.NewDecoder(res.Body).Decode(&response); err != nil {
-		return nil, errorf(err, "unable to parse GetPlayerSummaries response")
+	if err := c.call(ctx, epPlayerSummaries, params, &response, nil); err != nil {
+		return nil, err
 	}
 	return response.V.Players, nil
 }
 
+// the result object shared by the dota match history endpoints
+type dotaMatchList struct {
+	Status       int         `json:"status"`
+	StatusDetail string      `json:"statusDetail"`
+	NumResults   int         `json:"num_results"`
+	Total        int         `json:"total_results"`
+	Remaining    int         `json:"results_remaining"`
+	Matches      []DotaMatch `json:"matches"`
+}
+
+func (l dotaMatchList) check(ep endpoint) error {
+	if l.Status != 1 {
+		return ep.resultError(l.Status, l.StatusDetail)
+	}
+	return nil
+}
+
 func (c *Client) DotaMatchSequence(lastId uint64, n int) ([]DotaMatch, error) {
+	return c.DotaMatchSequenceContext(context.Background(), lastId, n)
+}
+
+func (c *Client) DotaMatchSequenceContext(ctx context.Context, lastId uint64, n int) ([]DotaMatch, error) {
 	// http://api.steampowered.com/IDOTA2Match_<ID>/GetMatchHistoryBySequenceNum/v1
-	url := fmt.Sprintf("https://api.steampowered.com/IDOTA2Match_570/GetMatchHistoryBySequenceNum/v1/?key=%s", c.key)
+	params := url.Values{}
 	if lastId > 0 {
-		url = fmt.Sprintf("%s&start_at_match_seq_num=%d", url, lastId)
+		params.Set("start_at_match_seq_num", strconv.FormatUint(lastId, 10))
 	}
 	if n > 0 {
-		url = fmt.Sprintf("%s&matches_requested=%d", url, n)
+		params.Set("matches_requested", strconv.Itoa(n))
 	}
-	fmt.Println(url)
 	var response struct {
-		V struct {
-			Status     int         `json:"status"`
-			NumResults int         `json:"num_results"`
-			Total      int         `json:"total_results"`
-			Remaining  int         `json:"results_remaining"`
-			Matches    []DotaMatch `json:"matches"`
-		} `json:"result"`
-	}
-	res, err := http.Get(url)
-	if err != nil {
-		return nil, errorf(err, "unable to get match history")
+		V dotaMatchList `json:"result"`
 	}
-	if err := json.NewDecoder(res.Body).Decode(&response); err != nil {
-		return nil, errorf(err, "unable to parse match history response")
+	check := func() error { return response.V.check(epMatchSequence) }
+	if err := c.call(ctx, epMatchSequence, params, &response, check); err != nil {
+		return nil, err
 	}
 	return response.V.Matches, nil
 }
 
 func (c *Client) DotaMatchHistory(lastId uint64, n int) ([]DotaMatch, error) {
-	url := fmt.Sprintf("https://api.steampowered.com/IDOTA2Match_570/GetMatchHistory/v0001/?key=%s", c.key)
-	if lastId > 0 {
-		url = fmt.Sprintf("%s&last_match_id=%d", url, lastId)
+	return c.DotaMatchHistoryContext(context.Background(), lastId, n)
+}
+
+func (c *Client) DotaMatchHistoryContext(ctx context.Context, lastId uint64, n int) ([]DotaMatch, error) {
+	page, err := c.DotaMatchHistoryPageContext(ctx, DotaMatchHistoryQuery{StartAtMatchId: lastId, MatchesRequested: n})
+	if err != nil {
+		return nil, err
 	}
-	if n > 0 {
-		url = fmt.Sprintf("%s&matches_requested=%d", url, n)
+	return page.Matches, nil
+}
+
+func (c *Client) DotaMatchHistoryPage(q DotaMatchHistoryQuery) (*DotaMatchPage, error) {
+	return c.DotaMatchHistoryPageContext(context.Background(), q)
+}
+
+// retrieves a single page of match history along with the counts steam
+// reports for the query as a whole
+func (c *Client) DotaMatchHistoryPageContext(ctx context.Context, q DotaMatchHistoryQuery) (*DotaMatchPage, error) {
+	if err := q.Validate(); err != nil {
+		return nil, err
 	}
-	fmt.Println(url)
 	var response struct {
-		V struct {
-			Status     int         `json:"status"`
-			NumResults int         `json:"num_results"`
-			Total      int         `json:"total_results"`
-			Remaining  int         `json:"results_remaining"`
-			Matches    []DotaMatch `json:"matches"`
-		} `json:"result"`
-	}
-	res, err := http.Get(url)
-	if err != nil {
-		return nil, errorf(err, "unable to get match history")
+		V dotaMatchList `json:"result"`
 	}
-	if err := json.NewDecoder(res.Body).Decode(&response); err != nil {
-		return nil, errorf(err, "unable to parse match history response")
+	check := func() error { return response.V.check(epMatchHistory) }
+	if err := c.call(ctx, epMatchHistory, q.params(), &response, check); err != nil {
+		return nil, err
 	}
-	return response.V.Matches, nil
+	return &DotaMatchPage{
+		Matches:   response.V.Matches,
+		Total:     response.V.Total,
+		Remaining: response.V.Remaining,
+	}, nil
+}
+
+func matchDetailsParams(id uint64) url.Values {
+	return url.Values{"match_id": {strconv.FormatUint(id, 10)}}
 }
 
 func (c *Client) DotaMatchDetails(id uint64) (*DotaMatchDetails, error) {
-	url := fmt.Sprintf("https://api.steampowered.com/IDOTA2Match_570/GetMatchDetails/v0001/?key=%s&match_id=%d", c.key, id)
-	res, err := http.Get(url)
-	if err != nil {
-		return nil, errorf(err, "unable to get match details")
-	}
+	return c.DotaMatchDetailsContext(context.Background(), id)
+}
+
+func (c *Client) DotaMatchDetailsContext(ctx context.Context, id uint64) (*DotaMatchDetails, error) {
+	params := matchDetailsParams(id)
 	var result struct {
-		V DotaMatchDetails `json:"result"`
+		V json.RawMessage `json:"result"`
 	}
-	if err := json.NewDecoder(res.Body).Decode(&result); err != nil {
-		return nil, errorf(err, "unable to parse match details")
+	var details DotaMatchDetails
+	err := c.call(ctx, epMatchDetails, params, &result, func() error {
+		// a missing match comes back as 200 OK with an error string in place
+		// of the match details
+		var status struct {
+			Error string `json:"error"`
+		}
+		if err := json.Unmarshal(result.V, &status); err != nil {
+			return epMatchDetails.errorf(err, "unable to parse match details")
+		}
+		if status.Error != "" {
+			e := epMatchDetails.errorf(nil, "no details for match %d", id)
+			e.StatusDetail = status.Error
+			e.kind = ErrNotFound
+			return e
+		}
+		if err := json.Unmarshal(result.V, &details); err != nil {
+			return epMatchDetails.errorf(err, "unable to parse match details")
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
 	}
-	return &result.V, nil
+	return &details, nil
 }
@@ -0,0 +1,34 @@
+package steam
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestResolveUserNumeric(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		t.Errorf("unexpected api call: %s", r.URL.Path)
+		http.NotFound(w, r)
+	}))
+	defer srv.Close()
+	c := NewClient("k", WithBaseURL(srv.URL), WithRetryPolicy(RetryPolicy{}))
+
+	for _, user := range []string{"76561197960287930", "22202", "STEAM_0:0:11101", "[U:1:22202]", "https://steamcommunity.com/profiles/76561197960287930/"} {
+		id, err := c.ResolveUser(user)
+		if err != nil {
+			t.Errorf("ResolveUser(%q) failed: %v", user, err)
+			continue
+		}
+		if id != 76561197960287930 {
+			t.Errorf("ResolveUser(%q) = %d", user, id)
+		}
+	}
+
+	// numeric input that isn't a valid id is an error, not a vanity name
+	for _, user := range []string{"0", "4503603922337793", "99999999999999999999"} {
+		if id, err := c.ResolveUser(user); err == nil {
+			t.Errorf("ResolveUser(%q) = %d, want an error", user, id)
+		}
+	}
+}
@@ -50,6 +50,7 @@ retrieves the list of currently supported api interfaces from steam
 		if err != nil {
 			bail(1, "error: %s", err)
 		}
+		defer res.Body.Close()
 		var response struct {
 			ApiList ApiList `json:"apilist"`
 		}
@@ -80,6 +81,7 @@ interfaces.
 		if err != nil {
 			bail(1, "error: %s", err)
 		}
+		defer res.Body.Close()
 		var response struct {
 			ApiList ApiList `json:"apilist"`
 		}
@@ -123,6 +125,7 @@ retrieve all method parameters for the IDOTA2Match_570 method GetMatchDetails:
 		if err != nil {
 			bail(1, "error: %s", err)
 		}
+		defer res.Body.Close()
 		var response struct {
 			ApiList ApiList `json:"apilist"`
 		}
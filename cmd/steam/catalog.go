@@ -0,0 +1,184 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"github.com/jordanorelli/steam"
+	"os"
+	"strings"
+)
+
+var cmd_dota_catalog_update = command{
+	help: `
+refreshes a dota catalog snapshot file with the live hero, item and ability
+lists, then reports what was added, removed or renamed since the previous
+snapshot. Run it against dota_catalog.json in the steam package to update the
+snapshot that's embedded in the library, e.g. with go generate.
+
+usage:
+    dota-catalog-update [-language <lang>] [-n] <file>
+
+flags:
+    -language <lang>   language of the localized names (default english)
+    -n                 report the changes without writing the file
+`,
+	handler: func(c *steam.Client, args ...string) {
+		var (
+			language string
+			dryRun   bool
+		)
+		flags := flag.NewFlagSet("dota-catalog-update", flag.ExitOnError)
+		flags.StringVar(&language, "language", "english", "")
+		flags.BoolVar(&dryRun, "n", false, "")
+		flags.Parse(args)
+		if flags.NArg() != 1 {
+			bail(1, "please provide exactly one snapshot file")
+		}
+		path := flags.Arg(0)
+
+		// a missing file is compared against the embedded snapshot
+		old := steam.EmbeddedDotaCatalog().Snapshot()
+		if b, err := os.ReadFile(path); err == nil {
+			old = steam.DotaCatalogSnapshot{}
+			if err := json.Unmarshal(b, &old); err != nil {
+				bail(1, "unable to parse %s: %v", path, err)
+			}
+		} else if !os.IsNotExist(err) {
+			bail(1, "%v", err)
+		}
+
+		cat, err := c.LoadDotaCatalog(language)
+		if err != nil {
+			bail(1, "%v", err)
+		}
+		snap := cat.Snapshot()
+		diff := steam.DiffDotaCatalogs(old, snap)
+		for _, change := range diff.Added {
+			fmt.Printf("added\t%s\t%d\t%s\n", change.Kind, change.Id, change.Name)
+		}
+		for _, change := range diff.Removed {
+			fmt.Printf("removed\t%s\t%d\t%s\n", change.Kind, change.Id, change.Name)
+		}
+		for _, change := range diff.Renamed {
+			fmt.Printf("renamed\t%s\t%d\t%s -> %s\n", change.Kind, change.Id, change.OldName, change.Name)
+		}
+		fmt.Fprintf(os.Stderr, "%d heroes, %d items, %d abilities: %d added, %d removed, %d renamed\n",
+			len(snap.Heroes), len(snap.Items), len(snap.Abilities),
+			len(diff.Added), len(diff.Removed), len(diff.Renamed))
+		if dryRun {
+			return
+		}
+
+		b, err := json.MarshalIndent(snap, "", "\t")
+		if err != nil {
+			bail(1, "%v", err)
+		}
+		if err := writeFileAtomic(path, append(b, '\n'), 0644); err != nil {
+			bail(1, "unable to write snapshot: %v", err)
+		}
+	},
+}
+
+// loads the catalog that hero, item and ability names are looked up in. By
+// default the snapshot embedded in the library is used; set STEAM_CATALOG=live
+// to fetch the current lists instead, in the language named by
+// STEAM_LANGUAGE. Failing to fetch them isn't fatal; the snapshot is used
+// instead.
+func loadCatalog(c *steam.Client) *steam.DotaCatalog {
+	if os.Getenv("STEAM_CATALOG") != "live" {
+		return steam.EmbeddedDotaCatalog()
+	}
+	language := os.Getenv("STEAM_LANGUAGE")
+	if language == "" {
+		language = "english"
+	}
+	cat, err := c.LoadDotaCatalog(language)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "unable to load dota catalog, using the embedded snapshot: %v\n", err)
+		return steam.EmbeddedDotaCatalog()
+	}
+	return cat
+}
+
+// a renderer for dota matches, using the catalog chosen by the environment
+func dotaRenderer(c *steam.Client) steam.DotaRenderer {
+	return steam.DotaRenderer{Catalog: loadCatalog(c), TimeLayout: timeLayout}
+}
+
+var cmd_dota_assets = command{
+	help: `
+downloads the cdn images of every hero, item and ability in the dota catalog,
+in every size, into an asset directory. Images are stored under the hash of
+their contents, and index.json in the directory maps each image url to its
+file. Images that are already in the directory aren't downloaded again.
+Talents and item abilities have no ability images of their own and are
+skipped.
+
+usage:
+    dota-assets [-heroes] [-items] [-abilities] [-cdn <url>] <dir>
+
+flags:
+    -heroes      only hero images
+    -items       only item images
+    -abilities   only ability images
+    -cdn <url>   root of the image cdn, to download from a mirror
+                 (default ` + string(steam.DefaultDotaCDN) + `)
+`,
+	handler: func(c *steam.Client, args ...string) {
+		var heroes, items, abilities bool
+		var cdn string
+		flags := flag.NewFlagSet("dota-assets", flag.ExitOnError)
+		flags.BoolVar(&heroes, "heroes", false, "")
+		flags.BoolVar(&items, "items", false, "")
+		flags.BoolVar(&abilities, "abilities", false, "")
+		flags.StringVar(&cdn, "cdn", string(steam.DefaultDotaCDN), "")
+		flags.Parse(args)
+		if flags.NArg() != 1 {
+			bail(1, "please provide exactly one asset directory")
+		}
+		if !heroes && !items && !abilities {
+			heroes, items, abilities = true, true, true
+		}
+
+		store, err := steam.NewDotaAssetStore(flags.Arg(0))
+		if err != nil {
+			bail(1, "%v", err)
+		}
+		cat := loadCatalog(c)
+		root := steam.DotaCDN(strings.TrimSuffix(cdn, "/"))
+		var urls []string
+		if heroes {
+			for _, h := range cat.Heroes() {
+				urls = append(urls, root.HeroImages(h)...)
+			}
+		}
+		if items {
+			for _, i := range cat.Items() {
+				urls = append(urls, root.ItemImages(i)...)
+			}
+		}
+		if abilities {
+			for _, a := range cat.Abilities() {
+				urls = append(urls, root.AbilityImages(a)...)
+			}
+		}
+
+		results, err := c.DownloadDotaAssets(store, urls)
+		failed := 0
+		for _, r := range results {
+			if r.Err != nil {
+				failed++
+				fmt.Fprintf(os.Stderr, "%s: %v\n", r.URL, r.Err)
+				continue
+			}
+			fmt.Printf("%s\t%s\n", r.URL, r.Path)
+		}
+		if err != nil {
+			bail(1, "%v", err)
+		}
+		if failed > 0 {
+			bail(1, "%d of %d images failed to download", failed, len(urls))
+		}
+	},
+}
@@ -1,6 +1,8 @@
 package main
 
 import (
+	"context"
+	"flag"
 	"fmt"
 	"github.com/jordanorelli/steam"
 	"io"
@@ -15,15 +17,18 @@ var commands map[string]command
 
 func init() {
 	commands = map[string]command{
-		"api-list":           cmd_api_list,
-		"api-interfaces":     cmd_api_interfaces,
-		"api-methods":        cmd_api_methods,
-		"api-params":         cmd_api_params,
-		"user-friends":       cmd_user_friends,
-		"user-id":            cmd_user_id,
-		"user-details":       cmd_user_details,
-		"dota-match-history": cmd_dota_match_history,
-		"dota-match-details": cmd_dota_match_details,
+		"api-list":            cmd_api_list,
+		"api-interfaces":      cmd_api_interfaces,
+		"api-methods":         cmd_api_methods,
+		"api-params":          cmd_api_params,
+		"user-friends":        cmd_user_friends,
+		"user-id":             cmd_user_id,
+		"user-details":        cmd_user_details,
+		"dota-match-history":  cmd_dota_match_history,
+		"dota-match-details":  cmd_dota_match_details,
+		"dota-crawl":          cmd_dota_crawl,
+		"dota-catalog-update": cmd_dota_catalog_update,
+		"dota-assets":         cmd_dota_assets,
 		"commands": command{
 			handler: func(c *steam.Client, args ...string) {
 				keys := make([]string, 0, len(commands))
@@ -60,17 +65,24 @@ retrieves info about a specific command
 	}
 }
 
+// users can be given to the user-* commands in any of these forms
+const userForms = `
+users may be given as a SteamID64, a steam2 id (STEAM_0:1:123), a steam3 id
+([U:1:246]), a steamcommunity.com/profiles/<id> or steamcommunity.com/id/<name>
+url, or a vanity name.
+`
+
 var cmd_user_friends = command{
 	help: `
 retrieves the provided user's list of friends
-`,
+` + userForms,
 	handler: func(c *steam.Client, args ...string) {
 		if len(args) < 1 {
-			bail(1, "please provide a user id")
+			bail(1, "please provide a user")
 		}
-		userid, err := strconv.ParseUint(args[0], 10, 64)
+		userid, err := c.ResolveUser(args[0])
 		if err != nil {
-			bail(1, "bad user id: %s", err)
+			bail(1, "bad user: %s", err)
 		}
 		friends, err := c.GetFriendList(userid)
 		if err != nil {
@@ -79,74 +91,170 @@ retrieves the provided user's list of friends
 		w := tabwriter.NewWriter(os.Stdout, 0, 8, 0, '\t', 0)
 		defer w.Flush()
 		for _, friend := range friends {
-			fmt.Fprintln(w, friend.Oneline())
+			fmt.Fprintln(w, friend.OnelineLayout(timeLayout))
 		}
 	},
 }
 
 var cmd_user_id = command{
 	help: `
-given a user's vanity url, retrieves their steam user id
+given a user, prints their SteamID64.
+
+usage:
+    user-id [-all] <user>
+` + userForms + `
+flags:
+    -all    print the id in each of its formats: SteamID64, steam2, steam3
+            and the 32-bit account id
 `,
 	handler: func(c *steam.Client, args ...string) {
-		userid, err := c.ResolveVanityUrl(args[0])
+		var all bool
+		flags := flag.NewFlagSet("user-id", flag.ExitOnError)
+		flags.BoolVar(&all, "all", false, "")
+		flags.Parse(args)
+		if flags.NArg() < 1 {
+			bail(1, "please provide a user")
+		}
+		userid, err := c.ResolveUser(flags.Arg(0))
 		if err != nil {
 			bail(1, err.Error())
 		}
-		fmt.Println(userid)
+		if !all {
+			fmt.Println(userid)
+			return
+		}
+		w := tabwriter.NewWriter(os.Stdout, 0, 8, 0, '\t', 0)
+		defer w.Flush()
+		fmt.Fprintf(w, "%s\t%s\t%s\t%d\n", userid, userid.Steam2(), userid.Steam3(), userid.AccountID())
 	},
 }
 
 var cmd_user_details = command{
 	help: `
-given a user's steam id, retrieves their user details
-`,
+given one or more users, retrieves their user details
+` + userForms,
 	handler: func(c *steam.Client, args ...string) {
 		if len(args) < 1 {
-			bail(1, "please provide a user id")
+			bail(1, "please provide a user")
 		}
-		ids := make([]uint64, 0, len(args))
+		ids := make([]steam.SteamID, 0, len(args))
 		for _, arg := range args {
-			userid, err := strconv.ParseUint(arg, 10, 64)
+			userid, err := c.ResolveUser(arg)
 			if err != nil {
-				bail(1, "bad user id: %s", err)
+				bail(1, "bad user: %s", err)
 			}
 			ids = append(ids, userid)
 		}
-		players, err := c.GetPlayerSummaries(ids...)
+		players, missing, err := c.GetPlayerSummariesBulk(ids)
 		if err != nil {
 			bail(1, "%v", err)
 		}
+		for _, id := range missing {
+			fmt.Fprintf(os.Stderr, "no details for user %s\n", id)
+		}
 		w := tabwriter.NewWriter(os.Stdout, 0, 8, 0, '\t', 0)
 		defer w.Flush()
 		for _, player := range players {
-			fmt.Fprintln(w, player.Oneline())
+			fmt.Fprintln(w, player.OnelineLayout(timeLayout))
 		}
 	},
 }
 
 var cmd_dota_match_history = command{
-	help: ``,
+	help: `
+retrieves recent dota matches, optionally filtered. By default a single page of
+up to 100 matches is shown; use -all to page through every match steam will
+return for the query (at most 500).
+
+flags:
+    -hero <id>          only matches with this hero
+    -game-mode <mode>   only matches in this game mode, given by name
+                        (all_pick, captains_mode, turbo, ...) or id
+    -skill <0-3>        only matches in this skill bracket (any, normal, high,
+                        very high). cannot be combined with -account
+    -min-players <n>    only matches with at least this many players
+    -account <user>     only matches played by this user, in any of the forms
+                        accepted by the user-* commands
+    -league <id>        only matches in this league
+    -start-at <id>      start at this match id instead of the most recent
+    -n <count>          matches per page, at most 100
+    -tournament         only tournament games
+    -all                page through all matches
+`,
 	handler: func(c *steam.Client, args ...string) {
-		matches, err := c.DotaMatchHistory(0, 0)
-		if err != nil {
+		var (
+			q       steam.DotaMatchHistoryQuery
+			account string
+			all     bool
+		)
+		flags := flag.NewFlagSet("dota-match-history", flag.ExitOnError)
+		flags.IntVar(&q.HeroId, "hero", 0, "")
+		flags.Var(&q.GameMode, "game-mode", "")
+		flags.IntVar(&q.Skill, "skill", 0, "")
+		flags.IntVar(&q.MinPlayers, "min-players", 0, "")
+		flags.StringVar(&account, "account", "", "")
+		flags.IntVar(&q.LeagueId, "league", 0, "")
+		flags.Uint64Var(&q.StartAtMatchId, "start-at", 0, "")
+		flags.IntVar(&q.MatchesRequested, "n", 0, "")
+		flags.BoolVar(&q.TournamentGamesOnly, "tournament", false, "")
+		flags.BoolVar(&all, "all", false, "")
+		flags.Parse(args)
+
+		if account != "" {
+			userid, err := c.ResolveUser(account)
+			if err != nil {
+				bail(1, "bad account: %s", err)
+			}
+			q.AccountId = userid.AccountID()
+		}
+		if err := q.Validate(); err != nil {
 			bail(1, "%v", err)
 		}
+
+		r := dotaRenderer(c)
 		w := tabwriter.NewWriter(os.Stdout, 0, 8, 0, '\t', 0)
 		defer w.Flush()
-		for _, match := range matches {
-			fmt.Fprintln(w, match.Oneline())
+		ctx := context.Background()
+		if !all {
+			page, err := c.DotaMatchHistoryPageContext(ctx, q)
+			if err != nil {
+				bail(1, "%v", err)
+			}
+			for _, match := range page.Matches {
+				fmt.Fprintln(w, r.Oneline(match))
+			}
+			return
+		}
+		it := c.IterDotaMatchHistory(q)
+		for it.Next(ctx) {
+			fmt.Fprintln(w, r.Oneline(it.Match()))
+		}
+		if err := it.Err(); err != nil {
+			w.Flush()
+			bail(1, "%v", err)
 		}
 	},
 }
 
 var cmd_dota_match_details = command{
-	help: ``,
+	help: `
+shows a dota match as a scoreboard, with both teams side by side.
+
+usage:
+    dota-match-details [-dump] <match id>
+
+flags:
+    -dump   list every field of the match and its players instead
+`,
 	handler: func(c *steam.Client, args ...string) {
-		if len(args) != 1 {
+		var raw bool
+		flags := flag.NewFlagSet("dota-match-details", flag.ExitOnError)
+		flags.BoolVar(&raw, "dump", false, "")
+		flags.Parse(args)
+		if flags.NArg() != 1 {
 			bail(1, "please provide exactly one match id")
 		}
-		id, err := strconv.ParseUint(args[0], 10, 64)
+		id, err := strconv.ParseUint(flags.Arg(0), 10, 64)
 		if err != nil {
 			bail(1, "bad match id: %s", err)
 		}
@@ -154,7 +262,12 @@ var cmd_dota_match_details = command{
 		if err != nil {
 			bail(1, "%v", err)
 		}
-		details.Display(os.Stdout)
+		r := dotaRenderer(c)
+		if raw {
+			r.Display(os.Stdout, *details)
+			return
+		}
+		r.Scoreboard(os.Stdout, *details)
 	},
 }
 
@@ -167,5 +280,8 @@ func dump(r *http.Response, e error) {
 	if e != nil {
 		bail(1, e.Error())
 	}
-	io.Copy(os.Stdout, r.Body)
+	defer r.Body.Close()
+	if _, err := io.Copy(os.Stdout, r.Body); err != nil {
+		bail(1, err.Error())
+	}
 }
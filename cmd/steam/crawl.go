@@ -0,0 +1,362 @@
+package main
+
+import (
+	"bufio"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"github.com/jordanorelli/steam"
+	"io"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"time"
+)
+
+var cmd_dota_crawl = command{
+	help: `
+crawls every public dota match in sequence number order, writing each match as
+a line of json. Output is split across files named
+dota-matches-<first seq num>.jsonl, which are rotated after a fixed number of
+matches. A file is written under a hidden temporary name and renamed once it
+is complete. The crawler's position is saved to a checkpoint file after each
+page is written, and a crawl resumes from the checkpoint when one exists. On
+resume, a file left incomplete by a crash is cut back to the checkpoint and
+given its final name. Runs until interrupted.
+
+flags:
+    -start <seq>         sequence number to start at when there is no checkpoint
+    -out <dir>           directory to write matches to (default .)
+    -checkpoint <file>   checkpoint file (default <out>/checkpoint)
+    -rotate <n>          matches per output file (default 50000)
+    -gzip                gzip output files
+    -stats <interval>    how often to report throughput and lag (default 30s)
+`,
+	handler: func(c *steam.Client, args ...string) {
+		var (
+			start      uint64
+			out        string
+			checkpoint string
+			rotate     int
+			compress   bool
+			interval   time.Duration
+		)
+		flags := flag.NewFlagSet("dota-crawl", flag.ExitOnError)
+		flags.Uint64Var(&start, "start", 0, "")
+		flags.StringVar(&out, "out", ".", "")
+		flags.StringVar(&checkpoint, "checkpoint", "", "")
+		flags.IntVar(&rotate, "rotate", 50000, "")
+		flags.BoolVar(&compress, "gzip", false, "")
+		flags.DurationVar(&interval, "stats", 30*time.Second, "")
+		flags.Parse(args)
+
+		if err := os.MkdirAll(out, 0755); err != nil {
+			bail(1, "unable to create output dir: %v", err)
+		}
+		if checkpoint == "" {
+			checkpoint = filepath.Join(out, "checkpoint")
+		}
+
+		ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+		defer stop()
+
+		store := steam.FileCheckpoint(checkpoint)
+		next, ok, err := store.Load(ctx)
+		if err != nil {
+			bail(1, "%v", err)
+		}
+		if !ok {
+			// nothing a previous run wrote was checkpointed
+			next = 0
+		}
+		w := &matchWriter{dir: out, rotate: rotate, gzip: compress}
+		if err := w.recover(next); err != nil {
+			bail(1, "%v", err)
+		}
+		crawler := &steam.MatchSequenceCrawler{
+			Client: c,
+			Store:  store,
+			Start:  start,
+		}
+
+		var stats crawlStats
+		go stats.report(ctx, interval, crawler)
+		err = crawler.Run(ctx, func(ctx context.Context, matches []steam.DotaMatch) error {
+			if err := w.WritePage(matches); err != nil {
+				return err
+			}
+			stats.add(matches)
+			return nil
+		})
+		if err != nil {
+			// the current file may hold matches past the checkpoint, so it
+			// keeps its partial name and is recovered on the next run
+			bail(1, "%v", err)
+		}
+		if err := w.Close(); err != nil {
+			bail(1, "%v", err)
+		}
+		fmt.Fprintf(os.Stderr, "stopped at seq num %d\n", crawler.Position())
+	},
+}
+
+// the suffix of an output file that is still being written. Such files are
+// hidden, named .dota-matches-<seq>.jsonl.partial, and get their final name
+// once complete, so every dota-matches-* file is whole.
+const partialSuffix = ".partial"
+
+// writes matches as json lines, rotating to a new file every rotate matches
+type matchWriter struct {
+	dir    string
+	rotate int
+	gzip   bool
+
+	f     *os.File
+	name  string
+	gz    *gzip.Writer
+	buf   *bufio.Writer
+	enc   *json.Encoder
+	count int
+}
+
+// writes a page of matches and syncs it to disk. The crawler saves its
+// checkpoint once the page is written, so the page has to be on disk by then.
+// Files are only rotated between pages, so a file never holds part of a page
+// that wasn't checkpointed by the time it gets its final name.
+func (w *matchWriter) WritePage(matches []steam.DotaMatch) error {
+	if len(matches) == 0 {
+		return nil
+	}
+	if w.f == nil || (w.rotate > 0 && w.count >= w.rotate) {
+		if err := w.open(matches[0].SeqNum); err != nil {
+			return err
+		}
+	}
+	for _, m := range matches {
+		if err := w.enc.Encode(m); err != nil {
+			return err
+		}
+		w.count++
+	}
+	return w.Sync()
+}
+
+func (w *matchWriter) ext() string {
+	if w.gzip {
+		return ".jsonl.gz"
+	}
+	return ".jsonl"
+}
+
+// opens a new output file under its partial name, closing the current one
+func (w *matchWriter) open(seq uint64) error {
+	if err := w.Close(); err != nil {
+		return err
+	}
+	name := filepath.Join(w.dir, fmt.Sprintf("dota-matches-%d%s", seq, w.ext()))
+	if _, err := os.Stat(name); err == nil {
+		return fmt.Errorf("%s already exists", name)
+	}
+	f, err := os.OpenFile(partialName(name), os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0644)
+	if err != nil {
+		return err
+	}
+	w.f, w.name = f, name
+	var dest io.Writer = f
+	if w.gzip {
+		w.gz = gzip.NewWriter(f)
+		dest = w.gz
+	}
+	w.buf = bufio.NewWriter(dest)
+	w.enc = json.NewEncoder(w.buf)
+	w.count = 0
+	return nil
+}
+
+// flushes everything written so far to disk
+func (w *matchWriter) Sync() error {
+	if w.f == nil {
+		return nil
+	}
+	if err := w.buf.Flush(); err != nil {
+		return err
+	}
+	if w.gz != nil {
+		if err := w.gz.Flush(); err != nil {
+			return err
+		}
+	}
+	return w.f.Sync()
+}
+
+// completes the current file and gives it its final name
+func (w *matchWriter) Close() error {
+	if w.f == nil {
+		return nil
+	}
+	err := w.buf.Flush()
+	if w.gz != nil {
+		if gerr := w.gz.Close(); err == nil {
+			err = gerr
+		}
+	}
+	if serr := w.f.Sync(); err == nil {
+		err = serr
+	}
+	if ferr := w.f.Close(); err == nil {
+		err = ferr
+	}
+	if err == nil {
+		err = os.Rename(partialName(w.name), w.name)
+	}
+	if err == nil {
+		err = syncDir(w.dir)
+	}
+	w.f, w.gz, w.buf, w.enc = nil, nil, nil, nil
+	return err
+}
+
+func partialName(name string) string {
+	return filepath.Join(filepath.Dir(name), "."+filepath.Base(name)+partialSuffix)
+}
+
+// recovers the files left partially written by a run that crashed. The
+// matches before next, the crawler's checkpoint, are kept and the file gets
+// its final name. The rest, along with any line cut off mid-write, are dropped,
+// since the crawler will fetch them again.
+func (w *matchWriter) recover(next uint64) error {
+	partials, err := filepath.Glob(filepath.Join(w.dir, ".dota-matches-*"+partialSuffix))
+	if err != nil {
+		return err
+	}
+	for _, p := range partials {
+		if err := recoverPartial(p, next); err != nil {
+			return fmt.Errorf("unable to recover %s: %v", p, err)
+		}
+	}
+	return nil
+}
+
+func recoverPartial(partial string, next uint64) error {
+	dir := filepath.Dir(partial)
+	name := filepath.Join(dir, strings.TrimSuffix(strings.TrimPrefix(filepath.Base(partial), "."), partialSuffix))
+	in, err := os.Open(partial)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+
+	tmp, err := os.CreateTemp(dir, ".tmp-")
+	if err != nil {
+		return err
+	}
+	n, err := copyCheckpointed(tmp, in, strings.HasSuffix(name, ".gz"), next)
+	if err == nil {
+		err = tmp.Sync()
+	}
+	if cerr := tmp.Close(); err == nil {
+		err = cerr
+	}
+	if err == nil && n > 0 {
+		err = os.Chmod(tmp.Name(), 0644)
+		if err == nil {
+			err = os.Rename(tmp.Name(), name)
+		}
+	}
+	if err != nil || n == 0 {
+		os.Remove(tmp.Name())
+	}
+	if err != nil {
+		return err
+	}
+	if err := os.Remove(partial); err != nil {
+		return err
+	}
+	return syncDir(dir)
+}
+
+// copies the complete lines of a partial file whose matches come before next,
+// returning how many were copied. Reading stops at the first line that was
+// cut off or isn't a match, which is where the crashed run stopped writing.
+func copyCheckpointed(dst io.Writer, src io.Reader, compressed bool, next uint64) (int, error) {
+	r := src
+	out := dst
+	var gzw *gzip.Writer
+	if compressed {
+		gz, err := gzip.NewReader(src)
+		if err != nil {
+			// cut off before any matches were written
+			return 0, nil
+		}
+		r = gz
+		gzw = gzip.NewWriter(dst)
+		out = gzw
+	}
+	br := bufio.NewReader(r)
+	bw := bufio.NewWriter(out)
+	n := 0
+	for {
+		line, err := br.ReadBytes('\n')
+		if err != nil {
+			break
+		}
+		var m steam.DotaMatch
+		if json.Unmarshal(line, &m) != nil || m.SeqNum >= next {
+			break
+		}
+		if _, err := bw.Write(line); err != nil {
+			return 0, err
+		}
+		n++
+	}
+	if err := bw.Flush(); err != nil {
+		return 0, err
+	}
+	if gzw != nil {
+		if err := gzw.Close(); err != nil {
+			return 0, err
+		}
+	}
+	return n, nil
+}
+
+// tracks crawl throughput and how far behind live the crawl is
+type crawlStats struct {
+	matches   atomic.Int64
+	lastStart atomic.Int64
+}
+
+func (s *crawlStats) add(matches []steam.DotaMatch) {
+	s.matches.Add(int64(len(matches)))
+	s.lastStart.Store(int64(matches[len(matches)-1].StartTime))
+}
+
+func (s *crawlStats) report(ctx context.Context, interval time.Duration, cr *steam.MatchSequenceCrawler) {
+	if interval <= 0 {
+		return
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	began := time.Now()
+	var prev int64
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case now := <-ticker.C:
+			total := s.matches.Load()
+			rate := float64(total-prev) / interval.Seconds()
+			prev = total
+			lag := "unknown"
+			if t := s.lastStart.Load(); t > 0 {
+				lag = now.Sub(time.Unix(t, 0)).Round(time.Second).String()
+			}
+			fmt.Fprintf(os.Stderr, "seq %d: %d matches in %s, %.1f matches/s, %s behind live\n",
+				cr.Position(), total, now.Sub(began).Round(time.Second), rate, lag)
+		}
+	}
+}
@@ -0,0 +1,187 @@
+package main
+
+import (
+	"bufio"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/jordanorelli/steam"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"testing"
+)
+
+// serves an endless run of matches, one per sequence number
+func sequenceServer(t *testing.T) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		start, _ := strconv.ParseUint(r.URL.Query().Get("start_at_match_seq_num"), 10, 64)
+		n, _ := strconv.Atoi(r.URL.Query().Get("matches_requested"))
+		if n <= 0 {
+			n = 100
+		}
+		matches := make([]steam.DotaMatch, n)
+		for i := range matches {
+			seq := start + uint64(i)
+			matches[i] = steam.DotaMatch{Id: seq * 10, SeqNum: seq}
+		}
+		json.NewEncoder(w).Encode(map[string]any{
+			"result": map[string]any{"status": 1, "matches": matches},
+		})
+	}))
+}
+
+var errCrash = errors.New("crash")
+
+func TestCrawlResumesAfterCrash(t *testing.T) {
+	const pageSize = 10
+	tests := []struct {
+		name    string
+		rotate  int
+		crashAt int // the page the first run dies on
+	}{
+		{"first page", 100, 1},
+		{"mid file", 100, 3},
+		{"after rotation", 2 * pageSize, 3},
+	}
+	for _, test := range tests {
+		for _, compress := range []bool{false, true} {
+			t.Run(fmt.Sprintf("%s/gzip=%t", test.name, compress), func(t *testing.T) {
+				srv := sequenceServer(t)
+				defer srv.Close()
+				c := steam.NewClient("k", steam.WithBaseURL(srv.URL), steam.WithLimiter(nil))
+				dir := t.TempDir()
+				checkpoint := steam.FileCheckpoint(filepath.Join(dir, "checkpoint"))
+
+				// the first run dies partway through a page, after half of
+				// the page and the start of the next line have reached the
+				// file but before the checkpoint is saved
+				w := &matchWriter{dir: dir, rotate: test.rotate, gzip: compress}
+				crawler := &steam.MatchSequenceCrawler{Client: c, Store: checkpoint, Start: 1000, PageSize: pageSize}
+				pages := 0
+				err := crawler.Run(context.Background(), func(ctx context.Context, matches []steam.DotaMatch) error {
+					if pages++; pages < test.crashAt {
+						return w.WritePage(matches)
+					}
+					if err := w.WritePage(matches[:pageSize/2]); err != nil {
+						return err
+					}
+					w.f.WriteString(`{"match_id":`)
+					w.f.Close()
+					return errCrash
+				})
+				if !errors.Is(err, errCrash) {
+					t.Fatalf("first run: %v", err)
+				}
+
+				// the second run recovers what was checkpointed and writes
+				// three more pages
+				next, ok, err := checkpoint.Load(context.Background())
+				if err != nil {
+					t.Fatal(err)
+				}
+				if !ok {
+					next = 0
+				}
+				w = &matchWriter{dir: dir, rotate: test.rotate, gzip: compress}
+				if err := w.recover(next); err != nil {
+					t.Fatal(err)
+				}
+				crawler = &steam.MatchSequenceCrawler{Client: c, Store: checkpoint, Start: 1000, PageSize: pageSize}
+				ctx, cancel := context.WithCancel(context.Background())
+				pages = 0
+				err = crawler.Run(ctx, func(ctx context.Context, matches []steam.DotaMatch) error {
+					if err := w.WritePage(matches); err != nil {
+						return err
+					}
+					if pages++; pages == 3 {
+						cancel()
+					}
+					return nil
+				})
+				if err != nil {
+					t.Fatalf("resumed run: %v", err)
+				}
+				if err := w.Close(); err != nil {
+					t.Fatal(err)
+				}
+
+				total := (test.crashAt - 1 + 3) * pageSize
+				seq, _, _ := checkpoint.Load(context.Background())
+				if seq != uint64(1000+total) {
+					t.Errorf("checkpoint is %d, want %d", seq, 1000+total)
+				}
+				if partials, _ := filepath.Glob(filepath.Join(dir, "*"+partialSuffix)); len(partials) > 0 {
+					t.Errorf("partial files left behind: %v", partials)
+				}
+
+				// every file is complete, and together they hold each match
+				// exactly once, in order
+				names, _ := filepath.Glob(filepath.Join(dir, "dota-matches-*"))
+				var seqs []uint64
+				for _, name := range names {
+					fileSeqs := readSeqs(t, name, compress)
+					if len(fileSeqs) == 0 {
+						t.Errorf("%s is empty", name)
+						continue
+					}
+					if len(fileSeqs) > test.rotate {
+						t.Errorf("%s holds %d matches, more than %d", name, len(fileSeqs), test.rotate)
+					}
+					want := fmt.Sprintf("dota-matches-%d.jsonl", fileSeqs[0])
+					if compress {
+						want += ".gz"
+					}
+					if filepath.Base(name) != want {
+						t.Errorf("%s starts at seq num %d", name, fileSeqs[0])
+					}
+					seqs = append(seqs, fileSeqs...)
+				}
+				sort.Slice(seqs, func(i, j int) bool { return seqs[i] < seqs[j] })
+				if len(seqs) != total {
+					t.Fatalf("files hold %d matches, want %d", len(seqs), total)
+				}
+				for i, s := range seqs {
+					if s != 1000+uint64(i) {
+						t.Fatalf("match %d has seq num %d", i, s)
+					}
+				}
+			})
+		}
+	}
+}
+
+func readSeqs(t *testing.T, name string, compress bool) []uint64 {
+	f, err := os.Open(name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	var r io.Reader = f
+	if compress {
+		gz, err := gzip.NewReader(f)
+		if err != nil {
+			t.Fatal(err)
+		}
+		r = gz
+	}
+	var seqs []uint64
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		var m steam.DotaMatch
+		if err := json.Unmarshal(scanner.Bytes(), &m); err != nil {
+			t.Fatalf("%s: %v", name, err)
+		}
+		seqs = append(seqs, m.SeqNum)
+	}
+	if err := scanner.Err(); err != nil {
+		t.Fatal(err)
+	}
+	return seqs
+}
@@ -4,7 +4,9 @@ import (
 	"fmt"
 	"github.com/jordanorelli/steam"
 	"io"
+	"log/slog"
 	"os"
+	"time"
 )
 
 func bail(code int, t string, args ...interface{}) {
@@ -18,13 +20,40 @@ func bail(code int, t string, args ...interface{}) {
 	os.Exit(code)
 }
 
+// the layout the cli formats times with. Empty means the library's default.
+var timeLayout string
+
 func main() {
 	key := os.Getenv("STEAM_KEY")
 	if key == "" {
 		bail(1, "no steam key provided. use the environment variable STEAM_KEY to provide an api key")
 	}
 
-	client := steam.NewClient(key)
+	var opts []steam.Option
+	if dir := os.Getenv("STEAM_CACHE_DIR"); dir != "" {
+		cache, err := steam.NewDirCache(dir)
+		if err != nil {
+			bail(1, "%v", err)
+		}
+		opts = append(opts, steam.WithCache(cache))
+	}
+
+	if os.Getenv("STEAM_TRACE") != "" {
+		logger := slog.New(slog.NewTextHandler(os.Stderr, &slog.HandlerOptions{Level: slog.LevelDebug}))
+		opts = append(opts, steam.WithTrace(steam.SlogTrace(logger)))
+	}
+
+	// times are shown in local time; STEAM_TIME=rfc3339 shows them in RFC 3339
+	// form instead
+	switch os.Getenv("STEAM_TIME") {
+	case "":
+	case "rfc3339":
+		timeLayout = time.RFC3339
+	default:
+		bail(1, "bad STEAM_TIME %q: the only supported format is rfc3339", os.Getenv("STEAM_TIME"))
+	}
+
+	client := steam.NewClient(key, opts...)
 	if len(os.Args) < 2 {
 		bail(1, "supply a subcommand pl0x")
 	}
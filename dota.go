@@ -2,6 +2,7 @@ package steam
 
 import (
 	"bytes"
+	"encoding/json"
 	"fmt"
 	"io"
 	"strings"
@@ -10,129 +11,296 @@ import (
 type DotaMatch struct {
 	Id            uint64            `json:"match_id"`
 	SeqNum        uint64            `json:"match_seq_num"`
-	StartTime     uint64            `json:"start_time"`
-	LobbyType     int               `json:"lobby_type"`
+	StartTime     Timestamp         `json:"start_time"`
+	LobbyType     LobbyType         `json:"lobby_type"`
 	RadiantTeamId int               `json:"radiant_team_id"`
 	DireTeamId    int               `json:"dire_team_id"`
 	Players       []DotaMatchPlayer `json:"players"`
+
+	// fields not listed above. The sequence number endpoint returns full match
+	// details, all of which end up here.
+	Extra map[string]json.RawMessage `json:"-"`
+}
+
+func (d *DotaMatch) UnmarshalJSON(b []byte) error {
+	type plain DotaMatch
+	extra, err := unmarshalExtra(b, (*plain)(d))
+	d.Extra = extra
+	return err
+}
+
+func (d DotaMatch) MarshalJSON() ([]byte, error) {
+	type plain DotaMatch
+	return marshalExtra(plain(d), d.Extra)
 }
 
 func (d DotaMatch) Oneline() string {
+	return DotaRenderer{}.Oneline(d)
+}
+
+// formats a match as tab-separated lines: one for the match, then one for
+// each player
+func (r DotaRenderer) Oneline(d DotaMatch) string {
+	cat := r.catalog()
 	var buf bytes.Buffer
-	fmt.Fprintf(&buf, "%d\t%d\t%d\t%d\t%d\t%d\n", d.Id, d.SeqNum, d.StartTime, d.LobbyType, d.RadiantTeamId, d.DireTeamId)
+	fmt.Fprintf(&buf, "%d\t%d\t%s\t%s\t%d\t%d\n", d.Id, d.SeqNum, d.StartTime.Format(r.TimeLayout), d.LobbyType, d.RadiantTeamId, d.DireTeamId)
 	for _, player := range d.Players {
-		fmt.Fprintf(&buf, "-\t-\t-\t%d\t%d\t%d\n", player.AccountId, player.PlayerSlot, player.HeroId)
+		fmt.Fprintf(&buf, "-\t-\t-\t%d\t%d\t%d\t%s\n", player.AccountId, player.PlayerSlot, player.HeroId, cat.HeroName(player.HeroId))
 	}
 	return strings.TrimSpace(buf.String())
 }
 
 type DotaMatchPlayer struct {
-	AccountId  uint64 `json:"account_id"`
-	PlayerSlot int    `json:"player_slot"`
-	HeroId     int    `json:"hero_id"`
+	AccountId  AccountID  `json:"account_id"`
+	PlayerSlot PlayerSlot `json:"player_slot"`
+	HeroId     int        `json:"hero_id"`
+
+	Extra map[string]json.RawMessage `json:"-"`
+}
+
+func (p *DotaMatchPlayer) UnmarshalJSON(b []byte) error {
+	type plain DotaMatchPlayer
+	extra, err := unmarshalExtra(b, (*plain)(p))
+	p.Extra = extra
+	return err
+}
+
+func (p DotaMatchPlayer) MarshalJSON() ([]byte, error) {
+	type plain DotaMatchPlayer
+	return marshalExtra(plain(p), p.Extra)
 }
 
 type DotaMatchDetails struct {
-	RadiantWin         bool                     `json:"radian_win"`
-	Duration           int                      `json:"duration"`
-	StartTime          int                      `json:"start_time"`
-	Id                 uint64                   `json:"match_id"`
-	SeqNum             uint64                   `json:"match_seq_num"`
-	TowerStatusRadiant int                      `json:"tower_status_radiant"`
-	TowerStatusDire    int                      `json:"tower_status_dire"`
-	Cluster            int                      `json:"cluster"`
-	FirstBloodTime     int                      `json:"first_blood_time"`
-	LobbyTime          int                      `json:"lobby_time"`
-	HumanPlayers       int                      `json:"human_players"`
-	LeagueId           int                      `json:"leagueid"`
-	PositiveVotes      int                      `json:"positive_votes"`
-	NegativeVotes      int                      `json:"negative_votes"`
-	GameMode           int                      `json:"game_mode"`
-	Engine             int                      `json:"engine"`
-	Players            []DotaMatchPlayerDetails `json:"players"`
+	RadiantWin            bool                     `json:"radiant_win"`
+	Duration              Seconds                  `json:"duration"`
+	PreGameDuration       Seconds                  `json:"pre_game_duration"`
+	StartTime             Timestamp                `json:"start_time"`
+	Id                    uint64                   `json:"match_id"`
+	SeqNum                uint64                   `json:"match_seq_num"`
+	TowerStatusRadiant    TowerStatus              `json:"tower_status_radiant"`
+	TowerStatusDire       TowerStatus              `json:"tower_status_dire"`
+	BarracksStatusRadiant BarracksStatus           `json:"barracks_status_radiant"`
+	BarracksStatusDire    BarracksStatus           `json:"barracks_status_dire"`
+	Cluster               int                      `json:"cluster"`
+	FirstBloodTime        Seconds                  `json:"first_blood_time"`
+	LobbyType             LobbyType                `json:"lobby_type"`
+	LobbyTime             Timestamp                `json:"lobby_time"`
+	HumanPlayers          int                      `json:"human_players"`
+	LeagueId              int                      `json:"leagueid"`
+	PositiveVotes         int                      `json:"positive_votes"`
+	NegativeVotes         int                      `json:"negative_votes"`
+	GameMode              GameMode                 `json:"game_mode"`
+	Flags                 int                      `json:"flags"`
+	Engine                int                      `json:"engine"`
+	RadiantScore          int                      `json:"radiant_score"`
+	DireScore             int                      `json:"dire_score"`
+	RadiantTeamId         int                      `json:"radiant_team_id"`
+	RadiantName           string                   `json:"radiant_name"`
+	RadiantLogo           uint64                   `json:"radiant_logo"`
+	RadiantTeamComplete   int                      `json:"radiant_team_complete"`
+	RadiantCaptain        AccountID                `json:"radiant_captain"`
+	DireTeamId            int                      `json:"dire_team_id"`
+	DireName              string                   `json:"dire_name"`
+	DireLogo              uint64                   `json:"dire_logo"`
+	DireTeamComplete      int                      `json:"dire_team_complete"`
+	DireCaptain           AccountID                `json:"dire_captain"`
+	PicksBans             []DotaPickBan            `json:"picks_bans"`
+	Players               []DotaMatchPlayerDetails `json:"players"`
+
+	// fields not listed above, kept so that newly added upstream fields
+	// survive a round trip through this type
+	Extra map[string]json.RawMessage `json:"-"`
+}
+
+func (d *DotaMatchDetails) UnmarshalJSON(b []byte) error {
+	type plain DotaMatchDetails
+	extra, err := unmarshalExtra(b, (*plain)(d))
+	d.Extra = extra
+	return err
+}
+
+func (d DotaMatchDetails) MarshalJSON() ([]byte, error) {
+	type plain DotaMatchDetails
+	return marshalExtra(plain(d), d.Extra)
+}
+
+// a single pick or ban in a captains mode draft
+type DotaPickBan struct {
+	IsPick bool `json:"is_pick"`
+	HeroId int  `json:"hero_id"`
+	Team   int  `json:"team"`
+	Order  int  `json:"order"`
 }
 
 func (d DotaMatchDetails) Display(w io.Writer) {
+	DotaRenderer{}.Display(w, d)
+}
+
+// lists every field of a match and its players
+func (r DotaRenderer) Display(w io.Writer, d DotaMatchDetails) {
+	cat := r.catalog()
 	if d.RadiantWin {
 		fmt.Fprintln(w, "Radiant Victory")
 	} else {
 		fmt.Fprintln(w, "Dire Victory")
 	}
-	fmt.Fprintf(w, "Duration: %d\n", d.Duration)
-	fmt.Fprintf(w, "StartTime: %d\n", d.StartTime)
+	fmt.Fprintf(w, "Score: %d - %d\n", d.RadiantScore, d.DireScore)
+	fmt.Fprintf(w, "Duration: %s\n", d.Duration)
+	fmt.Fprintf(w, "PreGameDuration: %s\n", d.PreGameDuration)
+	fmt.Fprintf(w, "StartTime: %s\n", d.StartTime.Format(r.TimeLayout))
 	fmt.Fprintf(w, "Id: %d\n", d.Id)
 	fmt.Fprintf(w, "SeqNum: %d\n", d.SeqNum)
-	fmt.Fprintf(w, "TowerStatusRadiant: %d\n", d.TowerStatusRadiant)
-	fmt.Fprintf(w, "TowerStatusDire: %d\n", d.TowerStatusDire)
+	fmt.Fprintf(w, "TowerStatusRadiant: %s\n", d.TowerStatusRadiant.Map())
+	fmt.Fprintf(w, "TowerStatusDire: %s\n", d.TowerStatusDire.Map())
+	fmt.Fprintf(w, "BarracksStatusRadiant: %s\n", d.BarracksStatusRadiant.Map())
+	fmt.Fprintf(w, "BarracksStatusDire: %s\n", d.BarracksStatusDire.Map())
 	fmt.Fprintf(w, "Cluster: %d\n", d.Cluster)
-	fmt.Fprintf(w, "FirstBloodTime: %d\n", d.FirstBloodTime)
-	fmt.Fprintf(w, "LobbyTime: %d\n", d.LobbyTime)
+	fmt.Fprintf(w, "FirstBloodTime: %s\n", d.FirstBloodTime)
+	fmt.Fprintf(w, "LobbyType: %s\n", d.LobbyType)
+	fmt.Fprintf(w, "LobbyTime: %s\n", d.LobbyTime.Format(r.TimeLayout))
 	fmt.Fprintf(w, "HumanPlayers: %d\n", d.HumanPlayers)
 	fmt.Fprintf(w, "LeagueId: %d\n", d.LeagueId)
 	fmt.Fprintf(w, "PositiveVotes: %d\n", d.PositiveVotes)
 	fmt.Fprintf(w, "NegativeVotes: %d\n", d.NegativeVotes)
-	fmt.Fprintf(w, "GameMode: %d\n", d.GameMode)
+	fmt.Fprintf(w, "GameMode: %s\n", d.GameMode)
+	fmt.Fprintf(w, "Flags: %d\n", d.Flags)
 	fmt.Fprintf(w, "Engine: %d\n", d.Engine)
+	if d.RadiantTeamId != 0 || d.DireTeamId != 0 {
+		fmt.Fprintf(w, "RadiantTeam: %d %s\n", d.RadiantTeamId, d.RadiantName)
+		fmt.Fprintf(w, "DireTeam: %d %s\n", d.DireTeamId, d.DireName)
+	}
+	for _, pb := range d.PicksBans {
+		action := "ban"
+		if pb.IsPick {
+			action = "pick"
+		}
+		fmt.Fprintf(w, "PickBan: %d team %d %s hero %d (%s)\n", pb.Order, pb.Team, action, pb.HeroId, cat.HeroName(pb.HeroId))
+	}
 	for _, player := range d.Players {
-		player.Display(w)
+		r.DisplayPlayer(w, player)
 	}
 }
 
 type DotaMatchPlayerDetails struct {
-	AccountId       uint64                `json:"account_id"`
-	PlayerSlot      int                   `json:"player_slot"`
-	HeroId          int                   `json:"hero_id"`
-	Item0           int                   `json:"item_0"`
-	Item1           int                   `json:"item_1"`
-	Item2           int                   `json:"item_2"`
-	Item3           int                   `json:"item_3"`
-	Item4           int                   `json:"item_4"`
-	Item5           int                   `json:"item_5"`
-	Kills           int                   `json:"kills"`
-	Deaths          int                   `json:"deaths"`
-	Assists         int                   `json:"assists"`
-	LeaverStatus    int                   `json:"leaver_status"`
-	Gold            int                   `json:"gold"`
-	LastHits        int                   `json:"last_hits"`
-	Denies          int                   `json:"denies"`
-	GoldPerMinute   int                   `json:"gold_per_min"`
-	XPPerMinute     int                   `json:"xp_per_min"`
-	GoldSpent       int                   `json:"gold_spent"`
-	HeroDamage      int                   `json:"hero_damage"`
-	TowerDamage     int                   `json:"tower_damage"`
-	HeroHealing     int                   `json:"hero_healing"`
-	Level           int                   `json:"level"`
-	AbilityUpgrades []DotaAbilityUpgrades `json:"ability_upgrades"`
+	AccountId         AccountID             `json:"account_id"`
+	PlayerSlot        PlayerSlot            `json:"player_slot"`
+	TeamNumber        int                   `json:"team_number"`
+	TeamSlot          int                   `json:"team_slot"`
+	HeroId            int                   `json:"hero_id"`
+	HeroVariant       int                   `json:"hero_variant"`
+	Item0             int                   `json:"item_0"`
+	Item1             int                   `json:"item_1"`
+	Item2             int                   `json:"item_2"`
+	Item3             int                   `json:"item_3"`
+	Item4             int                   `json:"item_4"`
+	Item5             int                   `json:"item_5"`
+	Backpack0         int                   `json:"backpack_0"`
+	Backpack1         int                   `json:"backpack_1"`
+	Backpack2         int                   `json:"backpack_2"`
+	ItemNeutral       int                   `json:"item_neutral"`
+	Kills             int                   `json:"kills"`
+	Deaths            int                   `json:"deaths"`
+	Assists           int                   `json:"assists"`
+	LeaverStatus      LeaverStatus          `json:"leaver_status"`
+	Gold              int                   `json:"gold"`
+	LastHits          int                   `json:"last_hits"`
+	Denies            int                   `json:"denies"`
+	GoldPerMinute     int                   `json:"gold_per_min"`
+	XPPerMinute       int                   `json:"xp_per_min"`
+	GoldSpent         int                   `json:"gold_spent"`
+	NetWorth          int                   `json:"net_worth"`
+	HeroDamage        int                   `json:"hero_damage"`
+	ScaledHeroDamage  int                   `json:"scaled_hero_damage"`
+	TowerDamage       int                   `json:"tower_damage"`
+	ScaledTowerDamage int                   `json:"scaled_tower_damage"`
+	HeroHealing       int                   `json:"hero_healing"`
+	ScaledHeroHealing int                   `json:"scaled_hero_healing"`
+	Level             int                   `json:"level"`
+	AghanimsScepter   int                   `json:"aghanims_scepter"`
+	AghanimsShard     int                   `json:"aghanims_shard"`
+	Moonshard         int                   `json:"moonshard"`
+	AbilityUpgrades   []DotaAbilityUpgrades `json:"ability_upgrades"`
+	AdditionalUnits   []DotaAdditionalUnit  `json:"additional_units"`
+
+	Extra map[string]json.RawMessage `json:"-"`
+}
+
+func (p *DotaMatchPlayerDetails) UnmarshalJSON(b []byte) error {
+	type plain DotaMatchPlayerDetails
+	extra, err := unmarshalExtra(b, (*plain)(p))
+	p.Extra = extra
+	return err
+}
+
+func (p DotaMatchPlayerDetails) MarshalJSON() ([]byte, error) {
+	type plain DotaMatchPlayerDetails
+	return marshalExtra(plain(p), p.Extra)
+}
+
+// a unit controlled by a player in addition to their hero, such as Lone
+// Druid's spirit bear, along with its items
+type DotaAdditionalUnit struct {
+	UnitName    string `json:"unitname"`
+	Item0       int    `json:"item_0"`
+	Item1       int    `json:"item_1"`
+	Item2       int    `json:"item_2"`
+	Item3       int    `json:"item_3"`
+	Item4       int    `json:"item_4"`
+	Item5       int    `json:"item_5"`
+	Backpack0   int    `json:"backpack_0"`
+	Backpack1   int    `json:"backpack_1"`
+	Backpack2   int    `json:"backpack_2"`
+	ItemNeutral int    `json:"item_neutral"`
 }
 
 func (p DotaMatchPlayerDetails) Display(w io.Writer) {
+	DotaRenderer{}.DisplayPlayer(w, p)
+}
+
+// lists every field of a player's match details
+func (r DotaRenderer) DisplayPlayer(w io.Writer, p DotaMatchPlayerDetails) {
+	cat := r.catalog()
 	fmt.Fprintf(w, "AccountId: %d\n", p.AccountId)
-	fmt.Fprintf(w, "PlayerSlot: %d\n", p.PlayerSlot)
-	fmt.Fprintf(w, "HeroId: %d\n", p.HeroId)
-	fmt.Fprintf(w, "Item0: %d\n", p.Item0)
-	fmt.Fprintf(w, "Item1: %d\n", p.Item1)
-	fmt.Fprintf(w, "Item2: %d\n", p.Item2)
-	fmt.Fprintf(w, "Item3: %d\n", p.Item3)
-	fmt.Fprintf(w, "Item4: %d\n", p.Item4)
-	fmt.Fprintf(w, "Item5: %d\n", p.Item5)
+	fmt.Fprintf(w, "PlayerSlot: %d (%s %d)\n", p.PlayerSlot, p.Team(), p.PlayerSlot.Position())
+	fmt.Fprintf(w, "HeroId: %d (%s)\n", p.HeroId, cat.HeroName(p.HeroId))
+	fmt.Fprintf(w, "Item0: %d (%s)\n", p.Item0, cat.ItemName(p.Item0))
+	fmt.Fprintf(w, "Item1: %d (%s)\n", p.Item1, cat.ItemName(p.Item1))
+	fmt.Fprintf(w, "Item2: %d (%s)\n", p.Item2, cat.ItemName(p.Item2))
+	fmt.Fprintf(w, "Item3: %d (%s)\n", p.Item3, cat.ItemName(p.Item3))
+	fmt.Fprintf(w, "Item4: %d (%s)\n", p.Item4, cat.ItemName(p.Item4))
+	fmt.Fprintf(w, "Item5: %d (%s)\n", p.Item5, cat.ItemName(p.Item5))
+	fmt.Fprintf(w, "Backpack0: %d (%s)\n", p.Backpack0, cat.ItemName(p.Backpack0))
+	fmt.Fprintf(w, "Backpack1: %d (%s)\n", p.Backpack1, cat.ItemName(p.Backpack1))
+	fmt.Fprintf(w, "Backpack2: %d (%s)\n", p.Backpack2, cat.ItemName(p.Backpack2))
+	fmt.Fprintf(w, "ItemNeutral: %d (%s)\n", p.ItemNeutral, cat.ItemName(p.ItemNeutral))
 	fmt.Fprintf(w, "Kills: %d\n", p.Kills)
 	fmt.Fprintf(w, "Deaths: %d\n", p.Deaths)
 	fmt.Fprintf(w, "Assists: %d\n", p.Assists)
-	fmt.Fprintf(w, "LeaverStatus: %d\n", p.LeaverStatus)
+	fmt.Fprintf(w, "LeaverStatus: %s\n", p.LeaverStatus)
 	fmt.Fprintf(w, "Gold: %d\n", p.Gold)
 	fmt.Fprintf(w, "LastHits: %d\n", p.LastHits)
 	fmt.Fprintf(w, "Denies: %d\n", p.Denies)
 	fmt.Fprintf(w, "GoldPerMinute: %d\n", p.GoldPerMinute)
 	fmt.Fprintf(w, "XPPerMinute: %d\n", p.XPPerMinute)
 	fmt.Fprintf(w, "GoldSpent: %d\n", p.GoldSpent)
+	fmt.Fprintf(w, "NetWorth: %d\n", p.NetWorth)
 	fmt.Fprintf(w, "HeroDamage: %d\n", p.HeroDamage)
+	fmt.Fprintf(w, "ScaledHeroDamage: %d\n", p.ScaledHeroDamage)
 	fmt.Fprintf(w, "TowerDamage: %d\n", p.TowerDamage)
 	fmt.Fprintf(w, "HeroHealing: %d\n", p.HeroHealing)
 	fmt.Fprintf(w, "Level: %d\n", p.Level)
+	fmt.Fprintf(w, "AghanimsScepter: %d\n", p.AghanimsScepter)
+	fmt.Fprintf(w, "AghanimsShard: %d\n", p.AghanimsShard)
+	for _, up := range p.AbilityUpgrades {
+		fmt.Fprintf(w, "AbilityUpgrade: level %d at %s %d (%s)\n", up.Level, up.Time, up.Ability, cat.AbilityName(up.Ability))
+	}
+	for _, unit := range p.AdditionalUnits {
+		fmt.Fprintf(w, "AdditionalUnit: %s\n", unit.UnitName)
+	}
 }
 
 type DotaAbilityUpgrades struct {
-	Ability int `json:"ability"`
-	Time    int `json:"time"`
-	Level   int `json:"level"`
+	Ability int     `json:"ability"`
+	Time    Seconds `json:"time"`
+	Level   int     `json:"level"`
 }
@@ -0,0 +1,313 @@
+package steam
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"sync"
+)
+
+// the root of an image cdn for dota. Its methods build the urls of hero, item
+// and ability images; the ImageURL methods of those types use DefaultDotaCDN.
+// Point one at a mirror to download from there instead.
+type DotaCDN string
+
+// valve's own image cdn
+const DefaultDotaCDN DotaCDN = "https://cdn.cloudflare.steamstatic.com/apps/dota2/images"
+
+// a size of hero image, named by the suffix the cdn gives it
+type DotaHeroImage string
+
+const (
+	DotaHeroSmall    DotaHeroImage = "sb.png"   // 59x33
+	DotaHeroLarge    DotaHeroImage = "lg.png"   // 205x115
+	DotaHeroFull     DotaHeroImage = "full.png" // 256x144
+	DotaHeroPortrait DotaHeroImage = "vert.jpg" // 235x272, upright
+)
+
+var DotaHeroImages = []DotaHeroImage{DotaHeroSmall, DotaHeroLarge, DotaHeroFull, DotaHeroPortrait}
+
+// a size of item image
+type DotaItemImage string
+
+const (
+	DotaItemLarge DotaItemImage = "lg.png" // 85x64
+)
+
+var DotaItemImages = []DotaItemImage{DotaItemLarge}
+
+// a size of ability image
+type DotaAbilityImage string
+
+const (
+	DotaAbilitySmall  DotaAbilityImage = "hp1.png" // 90x90
+	DotaAbilityMedium DotaAbilityImage = "hp2.png" // 105x105
+	DotaAbilityLarge  DotaAbilityImage = "lg.png"  // 128x128
+)
+
+var DotaAbilityImages = []DotaAbilityImage{DotaAbilitySmall, DotaAbilityMedium, DotaAbilityLarge}
+
+func (h DotaHero) ImageURL(size DotaHeroImage) string {
+	return DefaultDotaCDN.HeroImage(h, size)
+}
+
+// the url of the hero's minimap icon, 32x32
+func (h DotaHero) IconURL() string {
+	return DefaultDotaCDN.HeroIcon(h)
+}
+
+// the urls of every image of the hero: each size, then the minimap icon
+func (h DotaHero) ImageURLs() []string {
+	return DefaultDotaCDN.HeroImages(h)
+}
+
+func (cdn DotaCDN) HeroImage(h DotaHero, size DotaHeroImage) string {
+	return string(cdn) + "/heroes/" + h.ShortName() + "_" + string(size)
+}
+
+func (cdn DotaCDN) HeroIcon(h DotaHero) string {
+	return string(cdn) + "/dota_react/heroes/icons/" + h.ShortName() + ".png"
+}
+
+func (cdn DotaCDN) HeroImages(h DotaHero) []string {
+	urls := make([]string, 0, len(DotaHeroImages)+1)
+	for _, size := range DotaHeroImages {
+		urls = append(urls, cdn.HeroImage(h, size))
+	}
+	return append(urls, cdn.HeroIcon(h))
+}
+
+// the url of the item's image. Recipes all share one image.
+func (i DotaItem) ImageURL(size DotaItemImage) string {
+	return DefaultDotaCDN.ItemImage(i, size)
+}
+
+func (i DotaItem) ImageURLs() []string {
+	return DefaultDotaCDN.ItemImages(i)
+}
+
+func (cdn DotaCDN) ItemImage(i DotaItem, size DotaItemImage) string {
+	name := i.ShortName()
+	if i.Recipe != 0 || strings.HasPrefix(name, "recipe_") {
+		name = "recipe"
+	}
+	return string(cdn) + "/items/" + name + "_" + string(size)
+}
+
+func (cdn DotaCDN) ItemImages(i DotaItem) []string {
+	urls := make([]string, 0, len(DotaItemImages))
+	for _, size := range DotaItemImages {
+		urls = append(urls, cdn.ItemImage(i, size))
+	}
+	return urls
+}
+
+// reports whether the ability is a talent, e.g. special_bonus_attack_speed_20
+func (a DotaAbility) IsTalent() bool {
+	return strings.HasPrefix(a.Name, "special_bonus_")
+}
+
+// reports whether the ability belongs to an item, e.g. item_blink
+func (a DotaAbility) IsItem() bool {
+	return strings.HasPrefix(a.Name, "item_")
+}
+
+// the url of the ability's image. Talents have no image, and the images of
+// item abilities are those of their items, so for both this is empty.
+func (a DotaAbility) ImageURL(size DotaAbilityImage) string {
+	return DefaultDotaCDN.AbilityImage(a, size)
+}
+
+// the urls of every size of the ability's image, or nil if it has none; see
+// ImageURL
+func (a DotaAbility) ImageURLs() []string {
+	return DefaultDotaCDN.AbilityImages(a)
+}
+
+func (cdn DotaCDN) AbilityImage(a DotaAbility, size DotaAbilityImage) string {
+	if a.IsTalent() || a.IsItem() {
+		return ""
+	}
+	return string(cdn) + "/abilities/" + a.Name + "_" + string(size)
+}
+
+func (cdn DotaCDN) AbilityImages(a DotaAbility) []string {
+	if a.IsTalent() || a.IsItem() {
+		return nil
+	}
+	urls := make([]string, 0, len(DotaAbilityImages))
+	for _, size := range DotaAbilityImages {
+		urls = append(urls, cdn.AbilityImage(a, size))
+	}
+	return urls
+}
+
+// a DotaAssetStore is a directory of downloaded images. Each image is stored
+// once under the sha256 of its contents, so images the cdn serves at several
+// urls take up space once; index.json in the directory maps urls to files.
+// The index is written by Save. It is safe for concurrent use.
+type DotaAssetStore struct {
+	dir   string
+	mu    sync.Mutex
+	index map[string]string
+	dirty bool
+}
+
+// opens the asset store in dir, creating the directory if necessary
+func NewDotaAssetStore(dir string) (*DotaAssetStore, error) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, errorf(err, "unable to create asset dir")
+	}
+	s := &DotaAssetStore{dir: dir, index: make(map[string]string)}
+	b, err := os.ReadFile(s.indexPath())
+	if os.IsNotExist(err) {
+		return s, nil
+	}
+	if err != nil {
+		return nil, errorf(err, "unable to read asset index")
+	}
+	if err := json.Unmarshal(b, &s.index); err != nil {
+		return nil, errorf(err, "unable to parse asset index %s", s.indexPath())
+	}
+	return s, nil
+}
+
+func (s *DotaAssetStore) indexPath() string {
+	return filepath.Join(s.dir, "index.json")
+}
+
+// the path of the file holding the image at the given url, if it has been
+// downloaded
+func (s *DotaAssetStore) Path(u string) (string, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	name, ok := s.index[u]
+	if !ok {
+		return "", false
+	}
+	return filepath.Join(s.dir, name), true
+}
+
+// saves the image's contents under their hash and records the url in the
+// in-memory index
+func (s *DotaAssetStore) put(u string, body []byte) (string, error) {
+	sum := sha256.Sum256(body)
+	name := hex.EncodeToString(sum[:]) + assetExt(u)
+	p := filepath.Join(s.dir, name)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, err := os.Stat(p); os.IsNotExist(err) {
+		if err := writeFileAtomic(p, body, 0644); err != nil {
+			return "", errorf(err, "unable to save asset")
+		}
+	}
+	s.index[u] = name
+	s.dirty = true
+	return p, nil
+}
+
+// writes the index to index.json, if it has changed since it was loaded or
+// last saved
+func (s *DotaAssetStore) Save() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if !s.dirty {
+		return nil
+	}
+	b, err := json.MarshalIndent(s.index, "", "\t")
+	if err != nil {
+		return errorf(err, "unable to encode asset index")
+	}
+	if err := writeFileAtomic(s.indexPath(), b, 0644); err != nil {
+		return errorf(err, "unable to save asset index")
+	}
+	s.dirty = false
+	return nil
+}
+
+func assetExt(u string) string {
+	if parsed, err := url.Parse(u); err == nil {
+		if ext := path.Ext(parsed.Path); ext != "" {
+			return ext
+		}
+	}
+	return ".png"
+}
+
+var epAsset = endpoint{"cdn", "asset", ""}
+
+func (c *Client) DownloadDotaAsset(store *DotaAssetStore, u string) (string, error) {
+	return c.DownloadDotaAssetContext(context.Background(), store, u)
+}
+
+// downloads the image at the given url into the store and saves the store's
+// index, returning the path of the image's file. Images already in the store
+// aren't downloaded again. Cdn requests are traced, but aren't paced by the
+// client's limiter or counted against its budget.
+func (c *Client) DownloadDotaAssetContext(ctx context.Context, store *DotaAssetStore, u string) (string, error) {
+	p, err := c.downloadDotaAsset(ctx, store, u)
+	if err != nil {
+		return "", err
+	}
+	return p, store.Save()
+}
+
+func (c *Client) downloadDotaAsset(ctx context.Context, store *DotaAssetStore, u string) (string, error) {
+	if p, ok := store.Path(u); ok {
+		return p, nil
+	}
+	var body []byte
+	err := c.retry(ctx, epAsset, func() error {
+		res, err := c.send(ctx, epAsset, u)
+		if err != nil {
+			return err
+		}
+		body, err = c.read(ctx, epAsset, res)
+		return err
+	})
+	if err != nil {
+		return "", err
+	}
+	return store.put(u, body)
+}
+
+// the outcome of downloading a single image in a bulk download
+type DotaAssetResult struct {
+	URL  string
+	Path string
+	Err  error
+}
+
+func (c *Client) DownloadDotaAssets(store *DotaAssetStore, urls []string) ([]DotaAssetResult, error) {
+	return c.DownloadDotaAssetsContext(context.Background(), store, urls)
+}
+
+// downloads many images, several at a time, saving the store's index once
+// they're done. There's one result per url, in the order given; a failed
+// download doesn't stop the others. The returned error is from saving the
+// index.
+func (c *Client) DownloadDotaAssetsContext(ctx context.Context, store *DotaAssetStore, urls []string) ([]DotaAssetResult, error) {
+	results := make([]DotaAssetResult, len(urls))
+	var (
+		wg  sync.WaitGroup
+		sem = make(chan struct{}, bulkConcurrency)
+	)
+	for i, u := range urls {
+		wg.Add(1)
+		sem <- struct{}{}
+		go func(i int, u string) {
+			defer func() { <-sem; wg.Done() }()
+			p, err := c.downloadDotaAsset(ctx, store, u)
+			results[i] = DotaAssetResult{URL: u, Path: p, Err: err}
+		}(i, u)
+	}
+	wg.Wait()
+	return results, store.Save()
+}
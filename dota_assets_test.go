@@ -0,0 +1,122 @@
+package steam
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync"
+	"sync/atomic"
+	"testing"
+)
+
+func TestDotaAbilityImageURLs(t *testing.T) {
+	tests := []struct {
+		name string
+		want int
+	}{
+		{"antimage_mana_break", len(DotaAbilityImages)},
+		{"item_blink", 0},
+		{"special_bonus_attack_speed_20", 0},
+	}
+	for _, test := range tests {
+		a := DotaAbility{Name: test.name}
+		if urls := a.ImageURLs(); len(urls) != test.want {
+			t.Errorf("%s has image urls %v", test.name, urls)
+		}
+	}
+}
+
+func TestDownloadDotaAssets(t *testing.T) {
+	var requests atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		if r.URL.Path == "/heroes/missing_sb.png" {
+			http.NotFound(w, r)
+			return
+		}
+		w.Write([]byte("image"))
+	}))
+	defer srv.Close()
+	cdn := DotaCDN(srv.URL)
+
+	var (
+		mu     sync.Mutex
+		traced int
+	)
+	c := NewClient("k",
+		WithRetryPolicy(RetryPolicy{MaxAttempts: 1}),
+		WithDailyBudget(1),
+		WithTrace(func(RequestInfo) { mu.Lock(); traced++; mu.Unlock() }))
+	dir := t.TempDir()
+	store, err := NewDotaAssetStore(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	hero := DotaHero{Id: 1, Name: "npc_dota_hero_antimage"}
+	urls := append(cdn.HeroImages(hero), srv.URL+"/heroes/missing_sb.png")
+	results, err := c.DownloadDotaAssets(store, urls)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i, r := range results[:len(results)-1] {
+		if r.Err != nil {
+			t.Fatalf("%s: %v", r.URL, r.Err)
+		}
+		if r.URL != urls[i] {
+			t.Errorf("result %d is for %s, want %s", i, r.URL, urls[i])
+		}
+	}
+	if results[len(results)-1].Err == nil {
+		t.Error("missing image downloaded")
+	}
+	if traced != len(urls) {
+		t.Errorf("traced %d requests, want %d", traced, len(urls))
+	}
+
+	// every image has the same contents, so there's one file per extension
+	// plus the index
+	entries, _ := os.ReadDir(dir)
+	if len(entries) != 3 {
+		t.Errorf("asset dir holds %d entries", len(entries))
+	}
+
+	// a reopened store serves everything from the saved index
+	store, err = NewDotaAssetStore(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	before := requests.Load()
+	results, err = c.DownloadDotaAssets(store, urls[:len(urls)-1])
+	if err != nil {
+		t.Fatal(err)
+	}
+	if requests.Load() != before {
+		t.Error("images downloaded again")
+	}
+	if p, _ := store.Path(urls[0]); p != results[0].Path || filepath.Dir(p) != dir {
+		t.Errorf("path %s", p)
+	}
+}
+
+func TestDotaAssetStoreSavesIndexOnDemand(t *testing.T) {
+	dir := t.TempDir()
+	store, err := NewDotaAssetStore(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := store.put("http://cdn/a.png", []byte("a")); err != nil {
+		t.Fatal(err)
+	}
+	index := filepath.Join(dir, "index.json")
+	if _, err := os.Stat(index); !os.IsNotExist(err) {
+		t.Fatal("index written before Save")
+	}
+	if err := store.Save(); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(index); err != nil {
+		t.Fatal(err)
+	}
+}
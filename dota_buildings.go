@@ -0,0 +1,178 @@
+package steam
+
+import (
+	"fmt"
+	"strings"
+)
+
+type Lane int
+
+const (
+	LaneTop Lane = iota
+	LaneMid
+	LaneBot
+)
+
+func (l Lane) String() string {
+	switch l {
+	case LaneTop:
+		return "top"
+	case LaneMid:
+		return "mid"
+	case LaneBot:
+		return "bot"
+	default:
+		return fmt.Sprintf("Lane(%d)", int(l))
+	}
+}
+
+// a Tower identifies one of a team's eleven towers. Tier 4 towers guard the
+// ancient rather than a lane; the two of them are told apart by Lane, which is
+// either LaneTop or LaneBot.
+type Tower struct {
+	Lane Lane
+	Tier int
+}
+
+func (t Tower) String() string {
+	if t.Tier == 4 {
+		return fmt.Sprintf("%s ancient tower", t.Lane)
+	}
+	return fmt.Sprintf("%s tier %d tower", t.Lane, t.Tier)
+}
+
+// the towers, in the order of their bits in a TowerStatus
+var towers = []Tower{
+	{LaneTop, 1}, {LaneTop, 2}, {LaneTop, 3},
+	{LaneMid, 1}, {LaneMid, 2}, {LaneMid, 3},
+	{LaneBot, 1}, {LaneBot, 2}, {LaneBot, 3},
+	{LaneTop, 4}, {LaneBot, 4},
+}
+
+// a TowerStatus has a bit set for each of a team's towers that is still
+// standing
+type TowerStatus uint16
+
+func (s TowerStatus) IsStanding(t Tower) bool {
+	for i, tower := range towers {
+		if tower == t {
+			return s&(1<<uint(i)) != 0
+		}
+	}
+	return false
+}
+
+func (s TowerStatus) Standing() []Tower {
+	return s.filter(true)
+}
+
+func (s TowerStatus) Destroyed() []Tower {
+	return s.filter(false)
+}
+
+func (s TowerStatus) filter(standing bool) []Tower {
+	var out []Tower
+	for i, t := range towers {
+		if (s&(1<<uint(i)) != 0) == standing {
+			out = append(out, t)
+		}
+	}
+	return out
+}
+
+// renders the towers as a compact map, one character per tower from tier 1
+// inwards, with # for standing towers and . for destroyed ones:
+//
+//	top ##. mid #.. bot ### anc ##
+func (s TowerStatus) Map() string {
+	var buf strings.Builder
+	for i, t := range towers {
+		switch {
+		case i == 9:
+			buf.WriteString(" anc ")
+		case t.Tier == 1:
+			if i > 0 {
+				buf.WriteByte(' ')
+			}
+			buf.WriteString(t.Lane.String() + " ")
+		}
+		buf.WriteByte(standingChar(s&(1<<uint(i)) != 0))
+	}
+	return buf.String()
+}
+
+// a Barracks identifies one of a team's six barracks
+type Barracks struct {
+	Lane   Lane
+	Ranged bool
+}
+
+func (b Barracks) String() string {
+	if b.Ranged {
+		return fmt.Sprintf("%s ranged barracks", b.Lane)
+	}
+	return fmt.Sprintf("%s melee barracks", b.Lane)
+}
+
+// the barracks, in the order of their bits in a BarracksStatus
+var barracks = []Barracks{
+	{LaneTop, false}, {LaneTop, true},
+	{LaneMid, false}, {LaneMid, true},
+	{LaneBot, false}, {LaneBot, true},
+}
+
+// a BarracksStatus has a bit set for each of a team's barracks that is still
+// standing
+type BarracksStatus uint8
+
+func (s BarracksStatus) IsStanding(b Barracks) bool {
+	for i, rax := range barracks {
+		if rax == b {
+			return s&(1<<uint(i)) != 0
+		}
+	}
+	return false
+}
+
+func (s BarracksStatus) Standing() []Barracks {
+	return s.filter(true)
+}
+
+func (s BarracksStatus) Destroyed() []Barracks {
+	return s.filter(false)
+}
+
+func (s BarracksStatus) filter(standing bool) []Barracks {
+	var out []Barracks
+	for i, b := range barracks {
+		if (s&(1<<uint(i)) != 0) == standing {
+			out = append(out, b)
+		}
+	}
+	return out
+}
+
+// renders the barracks as a compact map, melee then ranged for each lane, with
+// # for standing barracks and . for destroyed ones:
+//
+//	top ## mid #. bot ..
+func (s BarracksStatus) Map() string {
+	var buf strings.Builder
+	for i, b := range barracks {
+		if !b.Ranged {
+			if i > 0 {
+				buf.WriteByte(' ')
+			}
+			buf.WriteString(b.Lane.String() + " ")
+		}
+		buf.WriteByte(standingChar(s&(1<<uint(i)) != 0))
+	}
+	return buf.String()
+}
+
+func standingChar(standing bool) byte {
+	if standing {
+		return '#'
+	}
+	return '.'
+}
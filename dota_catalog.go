@@ -0,0 +1,337 @@
+package steam
+
+import (
+	"context"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// the dota2.com data feed, which has the ability data that the steam web api
+// lacks
+const DefaultDotaDatafeedURL = "https://www.dota2.com/datafeed"
+
+var (
+	epHeroes    = endpoint{"IEconDOTA2_570", "GetHeroes", "v1"}
+	epGameItems = endpoint{"IEconDOTA2_570", "GetGameItems", "v1"}
+
+	// not part of the steam web api; see DotaAbilities
+	epAbilityList = endpoint{"datafeed", "abilitylist", ""}
+)
+
+type DotaHero struct {
+	Id            int    `json:"id"`
+	Name          string `json:"name"`
+	LocalizedName string `json:"localized_name"`
+}
+
+// the hero's name without its npc_dota_hero_ prefix, e.g. antimage
+func (h DotaHero) ShortName() string {
+	return strings.TrimPrefix(h.Name, "npc_dota_hero_")
+}
+
+type DotaItem struct {
+	Id            int    `json:"id"`
+	Name          string `json:"name"`
+	LocalizedName string `json:"localized_name"`
+	Cost          int    `json:"cost"`
+	SecretShop    int    `json:"secret_shop"`
+	SideShop      int    `json:"side_shop"`
+	Recipe        int    `json:"recipe"`
+}
+
+// the item's name without its item_ prefix, e.g. blink
+func (i DotaItem) ShortName() string {
+	return strings.TrimPrefix(i.Name, "item_")
+}
+
+type DotaAbility struct {
+	Id            int    `json:"id"`
+	Name          string `json:"name"`
+	LocalizedName string `json:"localized_name"`
+}
+
+func (c *Client) DotaHeroes(language string) ([]DotaHero, error) {
+	return c.DotaHeroesContext(context.Background(), language)
+}
+
+// retrieves the list of heroes, with names localized to the given language.
+// An empty language leaves the localized names out.
+func (c *Client) DotaHeroesContext(ctx context.Context, language string) ([]DotaHero, error) {
+	var response struct {
+		V struct {
+			Heroes []DotaHero `json:"heroes"`
+		} `json:"result"`
+	}
+	if err := c.call(ctx, epHeroes, languageParams(language), &response, nil); err != nil {
+		return nil, err
+	}
+	return response.V.Heroes, nil
+}
+
+func (c *Client) DotaItems(language string) ([]DotaItem, error) {
+	return c.DotaItemsContext(context.Background(), language)
+}
+
+// retrieves the list of items, with names localized to the given language
+func (c *Client) DotaItemsContext(ctx context.Context, language string) ([]DotaItem, error) {
+	var response struct {
+		V struct {
+			Items []DotaItem `json:"items"`
+		} `json:"result"`
+	}
+	if err := c.call(ctx, epGameItems, languageParams(language), &response, nil); err != nil {
+		return nil, err
+	}
+	return response.V.Items, nil
+}
+
+func (c *Client) DotaAbilities(language string) ([]DotaAbility, error) {
+	return c.DotaAbilitiesContext(context.Background(), language)
+}
+
+// retrieves the list of abilities, with names localized to the given
+// language. The steam web api has no ability data, so this comes from the
+// dota2.com data feed instead; it isn't paced by the client's limiter or
+// counted against its budget, but is traced and cached like any other call.
+// Item abilities are included alongside hero abilities.
+func (c *Client) DotaAbilitiesContext(ctx context.Context, language string) ([]DotaAbility, error) {
+	params := languageParams(language)
+	u := c.datafeed + "/abilitylist?" + params.Encode()
+	var response struct {
+		Result struct {
+			Data struct {
+				Abilities []struct {
+					Id      int    `json:"id"`
+					Name    string `json:"name"`
+					NameLoc string `json:"name_loc"`
+				} `json:"itemabilities"`
+			} `json:"data"`
+		} `json:"result"`
+	}
+	if err := c.fetch(ctx, epAbilityList, params, u, c.send, &response, nil); err != nil {
+		return nil, err
+	}
+	abilities := make([]DotaAbility, 0, len(response.Result.Data.Abilities))
+	for _, a := range response.Result.Data.Abilities {
+		abilities = append(abilities, DotaAbility{Id: a.Id, Name: a.Name, LocalizedName: a.NameLoc})
+	}
+	return abilities, nil
+}
+
+func languageParams(language string) url.Values {
+	if language == "" {
+		return nil
+	}
+	return url.Values{"language": {language}}
+}
+
+// a DotaCatalog maps the hero, item and ability ids that appear in match data
+// to their names. It is safe for concurrent use.
+type DotaCatalog struct {
+	mu        sync.RWMutex
+	language  string
+	heroes    map[int]DotaHero
+	items     map[int]DotaItem
+	abilities map[int]DotaAbility
+
+	// consulted for ids that aren't in this catalog
+	fallback *DotaCatalog
+}
+
+func NewDotaCatalog() *DotaCatalog {
+	return &DotaCatalog{
+		heroes:    make(map[int]DotaHero),
+		items:     make(map[int]DotaItem),
+		abilities: make(map[int]DotaAbility),
+	}
+}
+
+// a DotaRenderer formats matches for display, looking up hero, item and
+// ability names in its catalog. The zero value uses the embedded snapshot, so
+// names are available without calling steam, and the default timestamp
+// layout. The Oneline, Display and Scoreboard methods of the match types use
+// the zero value.
+type DotaRenderer struct {
+	// where names come from. If nil, the embedded snapshot is used.
+	Catalog *DotaCatalog
+
+	// the layout times are formatted with. If empty, DefaultTimestampLayout is
+	// used.
+	TimeLayout string
+}
+
+func (r DotaRenderer) catalog() *DotaCatalog {
+	if r.Catalog == nil {
+		return EmbeddedDotaCatalog()
+	}
+	return r.Catalog
+}
+
+func (c *Client) LoadDotaCatalog(language string) (*DotaCatalog, error) {
+	return c.LoadDotaCatalogContext(context.Background(), language)
+}
+
+// fetches the heroes, items and abilities in the given language, e.g.
+// "english". Responses are cached according to the client's cache policy.
+// Ids missing from the live data, such as those of retired items, are looked
+// up in the embedded snapshot.
+func (c *Client) LoadDotaCatalogContext(ctx context.Context, language string) (*DotaCatalog, error) {
+	heroes, err := c.DotaHeroesContext(ctx, language)
+	if err != nil {
+		return nil, err
+	}
+	items, err := c.DotaItemsContext(ctx, language)
+	if err != nil {
+		return nil, err
+	}
+	abilities, err := c.DotaAbilitiesContext(ctx, language)
+	if err != nil {
+		return nil, err
+	}
+	cat := NewDotaCatalog()
+	cat.language = language
+	cat.AddHeroes(heroes...)
+	cat.AddItems(items...)
+	cat.AddAbilities(abilities...)
+	cat.fallback = EmbeddedDotaCatalog()
+	return cat, nil
+}
+
+// the language the catalog's localized names are in
+func (cat *DotaCatalog) Language() string {
+	cat.mu.RLock()
+	defer cat.mu.RUnlock()
+	return cat.language
+}
+
+func (cat *DotaCatalog) AddHeroes(heroes ...DotaHero) {
+	cat.mu.Lock()
+	defer cat.mu.Unlock()
+	for _, h := range heroes {
+		cat.heroes[h.Id] = h
+	}
+}
+
+func (cat *DotaCatalog) AddItems(items ...DotaItem) {
+	cat.mu.Lock()
+	defer cat.mu.Unlock()
+	for _, i := range items {
+		cat.items[i.Id] = i
+	}
+}
+
+func (cat *DotaCatalog) AddAbilities(abilities ...DotaAbility) {
+	cat.mu.Lock()
+	defer cat.mu.Unlock()
+	for _, a := range abilities {
+		cat.abilities[a.Id] = a
+	}
+}
+
+func (cat *DotaCatalog) Hero(id int) (DotaHero, bool) {
+	cat.mu.RLock()
+	h, ok := cat.heroes[id]
+	cat.mu.RUnlock()
+	if !ok && cat.fallback != nil {
+		return cat.fallback.Hero(id)
+	}
+	return h, ok
+}
+
+func (cat *DotaCatalog) Item(id int) (DotaItem, bool) {
+	cat.mu.RLock()
+	i, ok := cat.items[id]
+	cat.mu.RUnlock()
+	if !ok && cat.fallback != nil {
+		return cat.fallback.Item(id)
+	}
+	return i, ok
+}
+
+func (cat *DotaCatalog) Ability(id int) (DotaAbility, bool) {
+	cat.mu.RLock()
+	a, ok := cat.abilities[id]
+	cat.mu.RUnlock()
+	if !ok && cat.fallback != nil {
+		return cat.fallback.Ability(id)
+	}
+	return a, ok
+}
+
+// every hero in the catalog, ordered by id. Entries only present in the
+// fallback snapshot aren't included.
+func (cat *DotaCatalog) Heroes() []DotaHero {
+	cat.mu.RLock()
+	defer cat.mu.RUnlock()
+	heroes := make([]DotaHero, 0, len(cat.heroes))
+	for _, h := range cat.heroes {
+		heroes = append(heroes, h)
+	}
+	sort.Slice(heroes, func(i, j int) bool { return heroes[i].Id < heroes[j].Id })
+	return heroes
+}
+
+// every item in the catalog, ordered by id. Entries only present in the
+// fallback snapshot aren't included.
+func (cat *DotaCatalog) Items() []DotaItem {
+	cat.mu.RLock()
+	defer cat.mu.RUnlock()
+	items := make([]DotaItem, 0, len(cat.items))
+	for _, i := range cat.items {
+		items = append(items, i)
+	}
+	sort.Slice(items, func(i, j int) bool { return items[i].Id < items[j].Id })
+	return items
+}
+
+// every ability in the catalog, ordered by id. Entries only present in the
+// fallback snapshot aren't included.
+func (cat *DotaCatalog) Abilities() []DotaAbility {
+	cat.mu.RLock()
+	defer cat.mu.RUnlock()
+	abilities := make([]DotaAbility, 0, len(cat.abilities))
+	for _, a := range cat.abilities {
+		abilities = append(abilities, a)
+	}
+	sort.Slice(abilities, func(i, j int) bool { return abilities[i].Id < abilities[j].Id })
+	return abilities
+}
+
+// the display name of a hero, falling back to its id when the hero isn't in
+// the catalog
+func (cat *DotaCatalog) HeroName(id int) string {
+	if h, ok := cat.Hero(id); ok {
+		return displayName(h.LocalizedName, h.Name)
+	}
+	return strconv.Itoa(id)
+}
+
+// the display name of an item, falling back to its id. Empty slots, id 0, are
+// rendered as "-".
+func (cat *DotaCatalog) ItemName(id int) string {
+	if id == 0 {
+		return "-"
+	}
+	if i, ok := cat.Item(id); ok {
+		return displayName(i.LocalizedName, i.Name)
+	}
+	return strconv.Itoa(id)
+}
+
+// the display name of an ability, falling back to its id
+func (cat *DotaCatalog) AbilityName(id int) string {
+	if a, ok := cat.Ability(id); ok {
+		return displayName(a.LocalizedName, a.Name)
+	}
+	return strconv.Itoa(id)
+}
+
+func displayName(localized, name string) string {
+	if localized != "" {
+		return localized
+	}
+	return name
+}
@@ -0,0 +1,145 @@
+package steam
+
+import (
+	_ "embed"
+	"encoding/json"
+	"sort"
+	"sync"
+)
+
+//go:generate go run ./cmd/steam dota-catalog-update dota_catalog.json
+
+// a snapshot of the hero, item and ability lists, for use when steam can't be
+// reached. The checked in file was seeded by hand and is incomplete: it has
+// the heroes but only the basic items and a handful of abilities. Refresh it
+// from the live endpoints with go generate, which runs dota-catalog-update.
+//
+//go:embed dota_catalog.json
+var dotaCatalogSnapshot []byte
+
+var embeddedCatalog = sync.OnceValue(func() *DotaCatalog {
+	var snap DotaCatalogSnapshot
+	if err := json.Unmarshal(dotaCatalogSnapshot, &snap); err != nil {
+		panic("steam: bad embedded dota catalog: " + err.Error())
+	}
+	return snap.Catalog()
+})
+
+// the catalog built from the snapshot embedded in this package. Its names are
+// in english and it may lag behind the live data by a patch or two.
+func EmbeddedDotaCatalog() *DotaCatalog {
+	return embeddedCatalog()
+}
+
+// the serialized form of a DotaCatalog, as stored in dota_catalog.json
+type DotaCatalogSnapshot struct {
+	Language  string        `json:"language"`
+	Heroes    []DotaHero    `json:"heroes"`
+	Items     []DotaItem    `json:"items"`
+	Abilities []DotaAbility `json:"abilities"`
+}
+
+// builds a catalog holding the snapshot's entries, with no fallback
+func (s DotaCatalogSnapshot) Catalog() *DotaCatalog {
+	cat := NewDotaCatalog()
+	cat.language = s.Language
+	cat.AddHeroes(s.Heroes...)
+	cat.AddItems(s.Items...)
+	cat.AddAbilities(s.Abilities...)
+	return cat
+}
+
+// the catalog's own entries, ordered by id
+func (cat *DotaCatalog) Snapshot() DotaCatalogSnapshot {
+	return DotaCatalogSnapshot{
+		Language:  cat.Language(),
+		Heroes:    cat.Heroes(),
+		Items:     cat.Items(),
+		Abilities: cat.Abilities(),
+	}
+}
+
+// a single difference between two catalog snapshots. Kind is one of "hero",
+// "item" or "ability"; OldName is only set for renames.
+type DotaCatalogChange struct {
+	Kind    string
+	Id      int
+	Name    string
+	OldName string
+}
+
+type DotaCatalogDiff struct {
+	Added   []DotaCatalogChange
+	Removed []DotaCatalogChange
+	Renamed []DotaCatalogChange
+}
+
+func (d DotaCatalogDiff) Empty() bool {
+	return len(d.Added) == 0 && len(d.Removed) == 0 && len(d.Renamed) == 0
+}
+
+// compares two snapshots by id. An entry counts as renamed when either its
+// internal name or its localized name changed.
+func DiffDotaCatalogs(old, new DotaCatalogSnapshot) DotaCatalogDiff {
+	var d DotaCatalogDiff
+	diff := func(kind string, before, after map[int][2]string) {
+		for id, names := range after {
+			prev, ok := before[id]
+			switch {
+			case !ok:
+				d.Added = append(d.Added, DotaCatalogChange{Kind: kind, Id: id, Name: displayName(names[1], names[0])})
+			case prev != names:
+				d.Renamed = append(d.Renamed, DotaCatalogChange{
+					Kind:    kind,
+					Id:      id,
+					Name:    displayName(names[1], names[0]),
+					OldName: displayName(prev[1], prev[0]),
+				})
+			}
+		}
+		for id, names := range before {
+			if _, ok := after[id]; !ok {
+				d.Removed = append(d.Removed, DotaCatalogChange{Kind: kind, Id: id, Name: displayName(names[1], names[0])})
+			}
+		}
+	}
+	diff("hero", heroNames(old.Heroes), heroNames(new.Heroes))
+	diff("item", itemNames(old.Items), itemNames(new.Items))
+	diff("ability", abilityNames(old.Abilities), abilityNames(new.Abilities))
+	for _, changes := range [][]DotaCatalogChange{d.Added, d.Removed, d.Renamed} {
+		sort.Slice(changes, func(i, j int) bool {
+			if changes[i].Kind != changes[j].Kind {
+				return catalogKindRank[changes[i].Kind] < catalogKindRank[changes[j].Kind]
+			}
+			return changes[i].Id < changes[j].Id
+		})
+	}
+	return d
+}
+
+// the order changes are listed in
+var catalogKindRank = map[string]int{"hero": 0, "item": 1, "ability": 2}
+
+func heroNames(heroes []DotaHero) map[int][2]string {
+	m := make(map[int][2]string, len(heroes))
+	for _, h := range heroes {
+		m[h.Id] = [2]string{h.Name, h.LocalizedName}
+	}
+	return m
+}
+
+func itemNames(items []DotaItem) map[int][2]string {
+	m := make(map[int][2]string, len(items))
+	for _, i := range items {
+		m[i.Id] = [2]string{i.Name, i.LocalizedName}
+	}
+	return m
+}
+
+func abilityNames(abilities []DotaAbility) map[int][2]string {
+	m := make(map[int][2]string, len(abilities))
+	for _, a := range abilities {
+		m[a.Id] = [2]string{a.Name, a.LocalizedName}
+	}
+	return m
+}
@@ -0,0 +1,99 @@
+package steam
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+)
+
+func TestDotaAbilitiesTracedAndCached(t *testing.T) {
+	var calls atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		if r.URL.Query().Get("key") != "" {
+			t.Error("api key sent to the data feed")
+		}
+		fmt.Fprint(w, `{"result":{"data":{"itemabilities":[{"id":5003,"name":"antimage_mana_break","name_loc":"Mana Break"}]}}}`)
+	}))
+	defer srv.Close()
+
+	var traced []RequestInfo
+	c := NewClient("k",
+		WithDotaDatafeedURL(srv.URL),
+		WithCache(NewMemoryCache(10)),
+		WithDailyBudget(1),
+		WithTrace(func(info RequestInfo) { traced = append(traced, info) }))
+
+	// three fetches on a budget of one: the data feed doesn't spend it
+	for i := 0; i < 3; i++ {
+		abilities, err := c.DotaAbilities("english")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(abilities) != 1 || abilities[0].LocalizedName != "Mana Break" {
+			t.Fatalf("abilities: %v", abilities)
+		}
+	}
+	if n := calls.Load(); n != 1 {
+		t.Errorf("data feed called %d times, want 1", n)
+	}
+	if len(traced) != 3 || traced[0].Cached || !traced[1].Cached || !traced[2].Cached {
+		t.Fatalf("traced: %+v", traced)
+	}
+	if !strings.HasPrefix(traced[0].URL, srv.URL+"/abilitylist?") || traced[0].StatusCode != 200 {
+		t.Errorf("traced %+v", traced[0])
+	}
+	if used := c.Budget().Used(); used != 0 {
+		t.Errorf("budget used %d", used)
+	}
+}
+
+func TestDiffDotaCatalogsOrder(t *testing.T) {
+	var old DotaCatalogSnapshot
+	new := DotaCatalogSnapshot{
+		Heroes:    []DotaHero{{Id: 2, Name: "npc_dota_hero_axe"}, {Id: 1, Name: "npc_dota_hero_antimage"}},
+		Items:     []DotaItem{{Id: 1, Name: "item_blink"}},
+		Abilities: []DotaAbility{{Id: 5003, Name: "antimage_mana_break"}},
+	}
+	diff := DiffDotaCatalogs(old, new)
+	var got []string
+	for _, change := range diff.Added {
+		got = append(got, fmt.Sprintf("%s %d", change.Kind, change.Id))
+	}
+	want := "hero 1, hero 2, item 1, ability 5003"
+	if strings.Join(got, ", ") != want {
+		t.Errorf("added %s, want %s", strings.Join(got, ", "), want)
+	}
+
+	// and back the other way
+	diff = DiffDotaCatalogs(new, old)
+	if len(diff.Removed) != 4 || diff.Removed[0].Kind != "hero" || diff.Removed[3].Kind != "ability" {
+		t.Errorf("removed %+v", diff.Removed)
+	}
+
+	renamed := new
+	renamed.Heroes = []DotaHero{{Id: 1, Name: "npc_dota_hero_antimage", LocalizedName: "Anti-Mage"}, new.Heroes[0]}
+	diff = DiffDotaCatalogs(new, renamed)
+	if len(diff.Renamed) != 1 || diff.Renamed[0].OldName != "npc_dota_hero_antimage" || diff.Renamed[0].Name != "Anti-Mage" {
+		t.Errorf("renamed %+v", diff.Renamed)
+	}
+}
+
+func TestDotaRendererCatalog(t *testing.T) {
+	cat := NewDotaCatalog()
+	cat.AddHeroes(DotaHero{Id: 1, Name: "npc_dota_hero_antimage", LocalizedName: "Magina"})
+	match := DotaMatch{Id: 1, Players: []DotaMatchPlayer{{HeroId: 1}}}
+
+	if s := (DotaRenderer{Catalog: cat}).Oneline(match); !strings.Contains(s, "Magina") {
+		t.Errorf("renderer ignored its catalog:\n%s", s)
+	}
+	if s := match.Oneline(); !strings.Contains(s, "Anti-Mage") {
+		t.Errorf("default renderer doesn't use the embedded snapshot:\n%s", s)
+	}
+	if s := EmbeddedDotaCatalog().HeroName(1); s != "Anti-Mage" {
+		t.Errorf("embedded snapshot changed by another catalog: hero 1 is %q", s)
+	}
+}
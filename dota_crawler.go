@@ -0,0 +1,243 @@
+package steam
+
+import (
+	"context"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// a CheckpointStore persists the position of a MatchSequenceCrawler so that
+// it can resume where it left off. The checkpoint is the next sequence number
+// to fetch.
+type CheckpointStore interface {
+	// returns the saved checkpoint, with ok false if nothing has been saved
+	Load(ctx context.Context) (seq uint64, ok bool, err error)
+	Save(ctx context.Context, seq uint64) error
+}
+
+// a CheckpointStore that lives only as long as the process
+type MemoryCheckpoint struct {
+	mu  sync.Mutex
+	seq uint64
+	ok  bool
+}
+
+func (m *MemoryCheckpoint) Load(ctx context.Context) (uint64, bool, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.seq, m.ok, nil
+}
+
+func (m *MemoryCheckpoint) Save(ctx context.Context, seq uint64) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.seq, m.ok = seq, true
+	return nil
+}
+
+// a CheckpointStore that keeps the checkpoint as a decimal number in a file
+type FileCheckpoint string
+
+func (f FileCheckpoint) Load(ctx context.Context) (uint64, bool, error) {
+	b, err := os.ReadFile(string(f))
+	if os.IsNotExist(err) {
+		return 0, false, nil
+	}
+	if err != nil {
+		return 0, false, errorf(err, "unable to read checkpoint")
+	}
+	seq, err := strconv.ParseUint(strings.TrimSpace(string(b)), 10, 64)
+	if err != nil {
+		return 0, false, errorf(err, "unable to parse checkpoint file %s", string(f))
+	}
+	return seq, true, nil
+}
+
+func (f FileCheckpoint) Save(ctx context.Context, seq uint64) error {
+	if err := writeFileAtomic(string(f), []byte(strconv.FormatUint(seq, 10)+"\n"), 0644); err != nil {
+		return errorf(err, "unable to save checkpoint")
+	}
+	return nil
+}
+
+// a MatchSequenceCrawler follows GetMatchHistoryBySequenceNum from a starting
+// point, delivering every public match in the order it was recorded. Matches
+// are delivered at least once: the checkpoint only moves past a page after the
+// page has been handled, so a crawler that dies mid-page repeats that page
+// when it resumes.
+type MatchSequenceCrawler struct {
+	Client *Client
+
+	// where the crawler's position is persisted. If nil, the position is only
+	// kept in memory.
+	Store CheckpointStore
+
+	// the sequence number to start at when the store has no checkpoint
+	Start uint64
+
+	// the number of matches to request per page, at most 100. Defaults to
+	// 100.
+	PageSize int
+
+	// once the crawler catches up to live matches it waits before polling
+	// again, starting at IdleDelay and doubling on each empty poll up to
+	// MaxIdleDelay. Default to 10 seconds and 2 minutes.
+	IdleDelay    time.Duration
+	MaxIdleDelay time.Duration
+
+	pos atomic.Uint64
+}
+
+// the next sequence number the crawler will fetch
+func (cr *MatchSequenceCrawler) Position() uint64 {
+	return cr.pos.Load()
+}
+
+func (cr *MatchSequenceCrawler) defaults() {
+	if cr.Store == nil {
+		cr.Store = &MemoryCheckpoint{}
+	}
+	if cr.PageSize <= 0 || cr.PageSize > 100 {
+		cr.PageSize = 100
+	}
+	if cr.IdleDelay <= 0 {
+		cr.IdleDelay = 10 * time.Second
+	}
+	if cr.MaxIdleDelay < cr.IdleDelay {
+		cr.MaxIdleDelay = 2 * time.Minute
+		if cr.MaxIdleDelay < cr.IdleDelay {
+			cr.MaxIdleDelay = cr.IdleDelay
+		}
+	}
+}
+
+// crawls until ctx is done or an error occurs, calling fn with each page of
+// matches. The checkpoint is saved after fn returns successfully; if fn
+// returns an error the crawl stops and the page will be delivered again on
+// the next run. Retryable api errors are waited out rather than returned.
+// Run returns nil when ctx is canceled.
+func (cr *MatchSequenceCrawler) Run(ctx context.Context, fn func(context.Context, []DotaMatch) error) error {
+	return cr.run(ctx, func(ctx context.Context, page []DotaMatch, next uint64) error {
+		if err := fn(ctx, page); err != nil {
+			return err
+		}
+		return cr.Store.Save(ctx, next)
+	})
+}
+
+// crawls in a separate goroutine, delivering matches on the returned channel.
+// The channel is closed when the crawl stops, after which wait returns the
+// error that stopped it. Since a match that has been received may not have
+// been processed yet, the checkpoint for a page is only saved once the first
+// match of the following page has been received.
+func (cr *MatchSequenceCrawler) Stream(ctx context.Context) (matches <-chan DotaMatch, wait func() error) {
+	c := make(chan DotaMatch)
+	done := make(chan struct{})
+	var err error
+	go func() {
+		defer close(done)
+		defer close(c)
+		var pending uint64
+		err = cr.run(ctx, func(ctx context.Context, page []DotaMatch, next uint64) error {
+			for i, m := range page {
+				select {
+				case c <- m:
+				case <-ctx.Done():
+					return nil
+				}
+				if i == 0 && pending != 0 {
+					if err := cr.Store.Save(ctx, pending); err != nil {
+						return err
+					}
+				}
+			}
+			pending = next
+			return nil
+		})
+	}()
+	return c, func() error {
+		<-done
+		return err
+	}
+}
+
+// the crawl loop shared by Run and Stream. handle is called with each page
+// and the sequence number that follows it.
+func (cr *MatchSequenceCrawler) run(ctx context.Context, handle func(context.Context, []DotaMatch, uint64) error) error {
+	cr.defaults()
+	seq, ok, err := cr.Store.Load(ctx)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		seq = cr.Start
+	}
+	cr.pos.Store(seq)
+
+	idle := cr.IdleDelay
+	for {
+		matches, err := cr.Client.DotaMatchSequenceContext(ctx, seq, cr.PageSize)
+		if ctx.Err() != nil {
+			return nil
+		}
+		if err != nil && !IsRetryable(err) {
+			return err
+		}
+
+		// the first request may start before the requested sequence number
+		// when it falls in a gap; never go backwards
+		page := matches[:0]
+		for _, m := range matches {
+			if m.SeqNum >= seq {
+				page = append(page, m)
+			}
+		}
+
+		if len(page) > 0 {
+			next := page[len(page)-1].SeqNum + 1
+			if err := handle(ctx, page, next); err != nil {
+				return err
+			}
+			if ctx.Err() != nil {
+				return nil
+			}
+			seq = next
+			cr.pos.Store(seq)
+		}
+
+		switch {
+		case err == nil && len(page) >= cr.PageSize:
+			// a full page means we're behind live, so keep going immediately
+			idle = cr.IdleDelay
+		case len(page) > 0:
+			// caught up, but matches are still coming in
+			idle = cr.IdleDelay
+			if !sleep(ctx, idle) {
+				return nil
+			}
+		default:
+			if !sleep(ctx, idle) {
+				return nil
+			}
+			if idle *= 2; idle > cr.MaxIdleDelay {
+				idle = cr.MaxIdleDelay
+			}
+		}
+	}
+}
+
+// sleeps for d, returning false if ctx is done first
+func sleep(ctx context.Context, d time.Duration) bool {
+	t := time.NewTimer(d)
+	defer t.Stop()
+	select {
+	case <-t.C:
+		return true
+	case <-ctx.Done():
+		return false
+	}
+}
@@ -0,0 +1,237 @@
+package steam
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// GameMode, LobbyType and LeaverStatus are the enumerated values that the
+// dota api reports as bare integers. Each has a short name used for parsing
+// (all_pick) and a display name used by String (All Pick). They are written to
+// json as integers, the way the api sends them, and read from either integers
+// or names.
+
+type GameMode int
+
+const (
+	GameModeNone GameMode = iota
+	GameModeAllPick
+	GameModeCaptainsMode
+	GameModeRandomDraft
+	GameModeSingleDraft
+	GameModeAllRandom
+	GameModeIntro
+	GameModeDiretide
+	GameModeReverseCaptainsMode
+	GameModeGreeviling
+	GameModeTutorial
+	GameModeMidOnly
+	GameModeLeastPlayed
+	GameModeLimitedHeroes
+	GameModeCompendiumMatchmaking
+	GameModeCustom
+	GameModeCaptainsDraft
+	GameModeBalancedDraft
+	GameModeAbilityDraft
+	GameModeEvent
+	GameModeAllRandomDeathmatch
+	GameMode1v1Mid
+	GameModeAllDraft
+	GameModeTurbo
+	GameModeMutation
+	GameModeCoachesChallenge
+)
+
+var gameModes = enumTable{
+	int(GameModeNone):                  {"none", "None"},
+	int(GameModeAllPick):               {"all_pick", "All Pick"},
+	int(GameModeCaptainsMode):          {"captains_mode", "Captains Mode"},
+	int(GameModeRandomDraft):           {"random_draft", "Random Draft"},
+	int(GameModeSingleDraft):           {"single_draft", "Single Draft"},
+	int(GameModeAllRandom):             {"all_random", "All Random"},
+	int(GameModeIntro):                 {"intro", "Intro"},
+	int(GameModeDiretide):              {"diretide", "Diretide"},
+	int(GameModeReverseCaptainsMode):   {"reverse_captains_mode", "Reverse Captains Mode"},
+	int(GameModeGreeviling):            {"greeviling", "The Greeviling"},
+	int(GameModeTutorial):              {"tutorial", "Tutorial"},
+	int(GameModeMidOnly):               {"mid_only", "Mid Only"},
+	int(GameModeLeastPlayed):           {"least_played", "Least Played"},
+	int(GameModeLimitedHeroes):         {"limited_heroes", "Limited Heroes"},
+	int(GameModeCompendiumMatchmaking): {"compendium_matchmaking", "Compendium Matchmaking"},
+	int(GameModeCustom):                {"custom", "Custom"},
+	int(GameModeCaptainsDraft):         {"captains_draft", "Captains Draft"},
+	int(GameModeBalancedDraft):         {"balanced_draft", "Balanced Draft"},
+	int(GameModeAbilityDraft):          {"ability_draft", "Ability Draft"},
+	int(GameModeEvent):                 {"event", "Event"},
+	int(GameModeAllRandomDeathmatch):   {"all_random_deathmatch", "All Random Deathmatch"},
+	int(GameMode1v1Mid):                {"1v1_mid", "1v1 Mid"},
+	int(GameModeAllDraft):              {"all_draft", "All Draft"},
+	int(GameModeTurbo):                 {"turbo", "Turbo"},
+	int(GameModeMutation):              {"mutation", "Mutation"},
+	int(GameModeCoachesChallenge):      {"coaches_challenge", "Coaches Challenge"},
+}
+
+func (m GameMode) String() string { return gameModes.display("GameMode", int(m)) }
+func (m GameMode) Name() string   { return gameModes.name("GameMode", int(m)) }
+
+func ParseGameMode(s string) (GameMode, error) {
+	v, err := gameModes.parse("game mode", s)
+	return GameMode(v), err
+}
+
+func (m *GameMode) UnmarshalJSON(b []byte) error {
+	v, err := gameModes.unmarshal("game mode", b)
+	*m = GameMode(v)
+	return err
+}
+
+// allows a GameMode to be used as a flag.Value
+func (m *GameMode) Set(s string) (err error) {
+	*m, err = ParseGameMode(s)
+	return err
+}
+
+type LobbyType int
+
+const (
+	LobbyTypeInvalid LobbyType = iota - 1
+	LobbyTypePublicMatchmaking
+	LobbyTypePractice
+	LobbyTypeTournament
+	LobbyTypeTutorial
+	LobbyTypeCoopBots
+	LobbyTypeTeamMatch
+	LobbyTypeSoloQueue
+	LobbyTypeRanked
+	LobbyType1v1Mid
+	LobbyTypeBattleCup
+	LobbyTypeLocalBots
+	LobbyTypeSpectator
+	LobbyTypeEvent
+	LobbyTypeGauntlet
+	LobbyTypeNewPlayer
+)
+
+var lobbyTypes = enumTable{
+	int(LobbyTypeInvalid):           {"invalid", "Invalid"},
+	int(LobbyTypePublicMatchmaking): {"public_matchmaking", "Public Matchmaking"},
+	int(LobbyTypePractice):          {"practice", "Practice"},
+	int(LobbyTypeTournament):        {"tournament", "Tournament"},
+	int(LobbyTypeTutorial):          {"tutorial", "Tutorial"},
+	int(LobbyTypeCoopBots):          {"coop_bots", "Co-op with Bots"},
+	int(LobbyTypeTeamMatch):         {"team_match", "Team Match"},
+	int(LobbyTypeSoloQueue):         {"solo_queue", "Solo Queue"},
+	int(LobbyTypeRanked):            {"ranked", "Ranked Matchmaking"},
+	int(LobbyType1v1Mid):            {"1v1_mid", "1v1 Mid"},
+	int(LobbyTypeBattleCup):         {"battle_cup", "Battle Cup"},
+	int(LobbyTypeLocalBots):         {"local_bots", "Local Bots"},
+	int(LobbyTypeSpectator):         {"spectator", "Spectator"},
+	int(LobbyTypeEvent):             {"event", "Event"},
+	int(LobbyTypeGauntlet):          {"gauntlet", "Gauntlet"},
+	int(LobbyTypeNewPlayer):         {"new_player", "New Player"},
+}
+
+func (t LobbyType) String() string { return lobbyTypes.display("LobbyType", int(t)) }
+func (t LobbyType) Name() string   { return lobbyTypes.name("LobbyType", int(t)) }
+
+func ParseLobbyType(s string) (LobbyType, error) {
+	v, err := lobbyTypes.parse("lobby type", s)
+	return LobbyType(v), err
+}
+
+func (t *LobbyType) UnmarshalJSON(b []byte) error {
+	v, err := lobbyTypes.unmarshal("lobby type", b)
+	*t = LobbyType(v)
+	return err
+}
+
+func (t *LobbyType) Set(s string) (err error) {
+	*t, err = ParseLobbyType(s)
+	return err
+}
+
+type LeaverStatus int
+
+const (
+	LeaverStatusNone LeaverStatus = iota
+	LeaverStatusDisconnected
+	LeaverStatusDisconnectedTooLong
+	LeaverStatusAbandoned
+	LeaverStatusAFK
+	LeaverStatusNeverConnected
+	LeaverStatusNeverConnectedTooLong
+)
+
+var leaverStatuses = enumTable{
+	int(LeaverStatusNone):                  {"none", "None"},
+	int(LeaverStatusDisconnected):          {"disconnected", "Disconnected"},
+	int(LeaverStatusDisconnectedTooLong):   {"disconnected_too_long", "Disconnected Too Long"},
+	int(LeaverStatusAbandoned):             {"abandoned", "Abandoned"},
+	int(LeaverStatusAFK):                   {"afk", "AFK"},
+	int(LeaverStatusNeverConnected):        {"never_connected", "Never Connected"},
+	int(LeaverStatusNeverConnectedTooLong): {"never_connected_too_long", "Never Connected Too Long"},
+}
+
+func (s LeaverStatus) String() string { return leaverStatuses.display("LeaverStatus", int(s)) }
+func (s LeaverStatus) Name() string   { return leaverStatuses.name("LeaverStatus", int(s)) }
+
+func ParseLeaverStatus(s string) (LeaverStatus, error) {
+	v, err := leaverStatuses.parse("leaver status", s)
+	return LeaverStatus(v), err
+}
+
+func (s *LeaverStatus) UnmarshalJSON(b []byte) error {
+	v, err := leaverStatuses.unmarshal("leaver status", b)
+	*s = LeaverStatus(v)
+	return err
+}
+
+// the names of one enumerated type's values
+type enumTable map[int]struct {
+	name    string
+	display string
+}
+
+func (t enumTable) display(kind string, v int) string {
+	if e, ok := t[v]; ok {
+		return e.display
+	}
+	return fmt.Sprintf("%s(%d)", kind, v)
+}
+
+func (t enumTable) name(kind string, v int) string {
+	if e, ok := t[v]; ok {
+		return e.name
+	}
+	return strconv.Itoa(v)
+}
+
+// parses a value from its name, its display name or its number. Names are
+// matched case insensitively, with dashes and spaces standing in for
+// underscores.
+func (t enumTable) parse(kind, s string) (int, error) {
+	if v, err := strconv.Atoi(s); err == nil {
+		return v, nil
+	}
+	norm := strings.NewReplacer("-", "_", " ", "_").Replace(strings.ToLower(strings.TrimSpace(s)))
+	for v, e := range t {
+		if norm == e.name || strings.EqualFold(s, e.display) {
+			return v, nil
+		}
+	}
+	return 0, errorf(nil, "unknown %s %q", kind, s)
+}
+
+func (t enumTable) unmarshal(kind string, b []byte) (int, error) {
+	var v int
+	if err := json.Unmarshal(b, &v); err == nil {
+		return v, nil
+	}
+	var s string
+	if err := json.Unmarshal(b, &s); err != nil {
+		return 0, errorf(err, "%s must be a number or a string", kind)
+	}
+	return t.parse(kind, s)
+}
@@ -0,0 +1,211 @@
+package steam
+
+import (
+	"context"
+	"net/url"
+	"strconv"
+)
+
+// steam never returns more than this many matches for a single match history
+// query, no matter how it is paged
+const MaxDotaMatchHistory = 500
+
+// skill brackets for filtering match history
+const (
+	DotaSkillAny = iota
+	DotaSkillNormal
+	DotaSkillHigh
+	DotaSkillVeryHigh
+)
+
+// the parameters of a GetMatchHistory query. Zero values leave a filter
+// unset.
+type DotaMatchHistoryQuery struct {
+	HeroId     int
+	GameMode   GameMode
+	Skill      int
+	MinPlayers int
+	AccountId  AccountID
+	LeagueId   int
+
+	// the id of the most recent match to return. Zero starts at the most
+	// recent match.
+	StartAtMatchId uint64
+
+	// the number of matches per page, at most 100. Zero uses steam's default
+	// of 100.
+	MatchesRequested int
+
+	TournamentGamesOnly bool
+}
+
+// checks the query for values and combinations that steam would reject or
+// silently ignore
+func (q DotaMatchHistoryQuery) Validate() error {
+	switch {
+	case q.HeroId < 0:
+		return errorf(nil, "invalid hero id %d", q.HeroId)
+	case q.GameMode < 0:
+		return errorf(nil, "invalid game mode %d", q.GameMode)
+	case q.Skill < DotaSkillAny || q.Skill > DotaSkillVeryHigh:
+		return errorf(nil, "invalid skill %d: must be between %d and %d", q.Skill, DotaSkillAny, DotaSkillVeryHigh)
+	case q.MinPlayers < 0 || q.MinPlayers > 10:
+		return errorf(nil, "invalid min players %d: must be between 0 and 10", q.MinPlayers)
+	case q.LeagueId < 0:
+		return errorf(nil, "invalid league id %d", q.LeagueId)
+	case q.MatchesRequested < 0 || q.MatchesRequested > 100:
+		return errorf(nil, "invalid matches requested %d: must be between 0 and 100", q.MatchesRequested)
+	case q.AccountId.IsAnonymous():
+		return errorf(nil, "cannot query the match history of the anonymous account")
+	case q.AccountId != 0 && q.Skill != DotaSkillAny:
+		// steam ignores the skill filter for account queries
+		return errorf(nil, "skill cannot be combined with an account id")
+	}
+	return nil
+}
+
+func (q DotaMatchHistoryQuery) params() url.Values {
+	params := url.Values{}
+	setInt := func(name string, v int) {
+		if v > 0 {
+			params.Set(name, strconv.Itoa(v))
+		}
+	}
+	setInt("hero_id", q.HeroId)
+	setInt("game_mode", int(q.GameMode))
+	setInt("skill", q.Skill)
+	setInt("min_players", q.MinPlayers)
+	setInt("league_id", q.LeagueId)
+	setInt("matches_requested", q.MatchesRequested)
+	if q.AccountId != 0 {
+		params.Set("account_id", strconv.FormatUint(uint64(q.AccountId), 10))
+	}
+	if q.StartAtMatchId > 0 {
+		params.Set("start_at_match_id", strconv.FormatUint(q.StartAtMatchId, 10))
+	}
+	if q.TournamentGamesOnly {
+		params.Set("tournament_games_only", "1")
+	}
+	return params
+}
+
+// a single page of match history
+type DotaMatchPage struct {
+	Matches []DotaMatch
+
+	// the total number of matches for the query, and the number that come
+	// after this page
+	Total     int
+	Remaining int
+}
+
+// a DotaMatchHistoryIterator walks every page of a match history query, from
+// the most recent match backwards:
+//
+//	it := client.IterDotaMatchHistory(q)
+//	for it.Next(ctx) {
+//		match := it.Match()
+//	}
+//	if err := it.Err(); err != nil {
+//	}
+type DotaMatchHistoryIterator struct {
+	c *Client
+	q DotaMatchHistoryQuery
+
+	page      []DotaMatch
+	match     DotaMatch
+	total     int
+	remaining int
+	seen      int
+	started   bool
+	done      bool
+	err       error
+}
+
+func (c *Client) IterDotaMatchHistory(q DotaMatchHistoryQuery) *DotaMatchHistoryIterator {
+	return &DotaMatchHistoryIterator{c: c, q: q}
+}
+
+// advances to the next match, fetching the next page when the current one is
+// used up. Returns false once every match has been seen, when steam's limit of
+// MaxDotaMatchHistory matches is reached, or when an error occurs, including
+// the cancellation of ctx.
+func (it *DotaMatchHistoryIterator) Next(ctx context.Context) bool {
+	for len(it.page) == 0 {
+		if it.done || it.err != nil {
+			return false
+		}
+		if err := ctx.Err(); err != nil {
+			it.err = epMatchHistory.errorf(err, "request canceled")
+			return false
+		}
+		if !it.fetch(ctx) {
+			return false
+		}
+	}
+	it.match, it.page = it.page[0], it.page[1:]
+	it.seen++
+	it.q.StartAtMatchId = it.match.Id - 1
+	if it.seen >= MaxDotaMatchHistory || it.match.Id <= 1 {
+		it.done = true
+		it.page = nil
+	}
+	return true
+}
+
+// fetches the next page. Returns false if there was nothing left to fetch.
+func (it *DotaMatchHistoryIterator) fetch(ctx context.Context) bool {
+	if it.started && it.remaining <= 0 {
+		it.done = true
+		return false
+	}
+	page, err := it.c.DotaMatchHistoryPageContext(ctx, it.q)
+	if err != nil {
+		it.err = err
+		return false
+	}
+	it.started = true
+	it.total = page.Total
+	it.remaining = page.Remaining
+
+	// each page starts one below the last match of the previous page, but
+	// steam has been known to return the boundary match again
+	for _, m := range page.Matches {
+		if it.seen > 0 && m.Id > it.q.StartAtMatchId {
+			continue
+		}
+		it.page = append(it.page, m)
+	}
+	if len(it.page) == 0 {
+		it.done = true
+		return false
+	}
+	return true
+}
+
+// the current match
+func (it *DotaMatchHistoryIterator) Match() DotaMatch {
+	return it.match
+}
+
+// the error that stopped iteration, if any
+func (it *DotaMatchHistoryIterator) Err() error {
+	return it.err
+}
+
+// the total number of matches steam reports for the query
+func (it *DotaMatchHistoryIterator) Total() int {
+	return it.total
+}
+
+// the number of matches not yet returned by Next
+func (it *DotaMatchHistoryIterator) Remaining() int {
+	if it.done {
+		return 0
+	}
+	n := it.remaining + len(it.page)
+	if max := MaxDotaMatchHistory - it.seen; n > max {
+		n = max
+	}
+	return n
+}
@@ -0,0 +1,28 @@
+//go:build go1.23
+
+package steam
+
+import (
+	"context"
+	"iter"
+)
+
+func (c *Client) DotaMatchHistorySeq(q DotaMatchHistoryQuery) iter.Seq2[DotaMatch, error] {
+	return c.DotaMatchHistorySeqContext(context.Background(), q)
+}
+
+// returns a sequence over every match of a match history query. If iteration
+// stops because of an error, the error is yielded as the final element.
+func (c *Client) DotaMatchHistorySeqContext(ctx context.Context, q DotaMatchHistoryQuery) iter.Seq2[DotaMatch, error] {
+	return func(yield func(DotaMatch, error) bool) {
+		it := c.IterDotaMatchHistory(q)
+		for it.Next(ctx) {
+			if !yield(it.Match(), nil) {
+				return
+			}
+		}
+		if err := it.Err(); err != nil {
+			yield(DotaMatch{}, err)
+		}
+	}
+}
@@ -0,0 +1,177 @@
+package steam
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"strings"
+	"text/tabwriter"
+	"unicode/utf8"
+)
+
+// the narrowest a team's half of the scoreboard gets, so that item lists
+// wrap at a readable width
+const minScoreboardWidth = 48
+
+// writes a scoreboard for the match: a header with the mode, start time and
+// duration, a winner banner, and the two teams side by side, one row per
+// player with their hero, kills/deaths/assists, last hits/denies, gold and
+// experience per minute, net worth, hero damage and items.
+func (d DotaMatchDetails) Scoreboard(w io.Writer) {
+	DotaRenderer{}.Scoreboard(w, d)
+}
+
+// writes a scoreboard for the match, as described for
+// DotaMatchDetails.Scoreboard
+func (r DotaRenderer) Scoreboard(w io.Writer, d DotaMatchDetails) {
+	cat := r.catalog()
+	radiant := scoreboardTeam(cat, d.teamName(Radiant), d.RadiantPlayers())
+	dire := scoreboardTeam(cat, d.teamName(Dire), d.DirePlayers())
+	left := width(radiant)
+	total := left + len(scoreboardGutter) + width(dire)
+
+	fmt.Fprintf(w, "Match %d  %s  %s\n", d.Id, d.GameMode, d.LobbyType)
+	fmt.Fprintf(w, "Started %s  Duration %s  First blood %s\n",
+		d.StartTime.Format(r.TimeLayout), d.Duration, d.FirstBloodTime)
+	fmt.Fprintln(w)
+	fmt.Fprintln(w, center(fmt.Sprintf("=== %s VICTORY ===", strings.ToUpper(d.teamName(d.Winner()))), total))
+	fmt.Fprintln(w, center(fmt.Sprintf("%s %d - %d %s", d.teamName(Radiant), d.RadiantScore, d.DireScore, d.teamName(Dire)), total))
+	fmt.Fprintln(w)
+
+	for i := 0; i < len(radiant) || i < len(dire); i++ {
+		var l, r string
+		if i < len(radiant) {
+			l = radiant[i]
+		}
+		if i < len(dire) {
+			r = dire[i]
+		}
+		fmt.Fprintln(w, strings.TrimRight(pad(l, left)+scoreboardGutter+r, " "))
+	}
+}
+
+const scoreboardGutter = "  |  "
+
+func (d DotaMatchDetails) teamName(t DotaTeam) string {
+	if t == Radiant && d.RadiantName != "" {
+		return d.RadiantName
+	}
+	if t == Dire && d.DireName != "" {
+		return d.DireName
+	}
+	return t.String()
+}
+
+// renders one team's half of the scoreboard: a header line, then for each
+// player a line of stats followed by their items, wrapped to the width of the
+// stats. Every player block is padded to the same height so that the two
+// halves line up.
+func scoreboardTeam(cat *DotaCatalog, name string, players []DotaMatchPlayerDetails) []string {
+	var buf bytes.Buffer
+	tw := tabwriter.NewWriter(&buf, 0, 8, 2, ' ', 0)
+	fmt.Fprintf(tw, "%s\tLvl\tK/D/A\tLH/DN\tGPM/XPM\tNW\tHD\t\n", name)
+	for _, p := range players {
+		hero := cat.HeroName(p.HeroId)
+		if p.LeaverStatus != LeaverStatusNone {
+			hero += " (" + p.LeaverStatus.String() + ")"
+		}
+		fmt.Fprintf(tw, "%s\t%d\t%d/%d/%d\t%d/%d\t%d/%d\t%s\t%s\t\n",
+			hero, p.Level,
+			p.Kills, p.Deaths, p.Assists,
+			p.LastHits, p.Denies,
+			p.GoldPerMinute, p.XPPerMinute,
+			formatCount(p.NetWorth), formatCount(p.HeroDamage))
+	}
+	tw.Flush()
+	stats := strings.Split(strings.TrimRight(buf.String(), "\n"), "\n")
+	for i := range stats {
+		stats[i] = strings.TrimRight(stats[i], " ")
+	}
+	wrapAt := width(stats)
+	if wrapAt < minScoreboardWidth {
+		wrapAt = minScoreboardWidth
+	}
+
+	items := make([][]string, len(players))
+	height := 0
+	for i, p := range players {
+		items[i] = wrap(p.itemNames(cat), "  ", wrapAt)
+		if len(items[i]) > height {
+			height = len(items[i])
+		}
+	}
+
+	lines := []string{stats[0]}
+	for i := range players {
+		lines = append(lines, stats[i+1])
+		lines = append(lines, items[i]...)
+		for j := len(items[i]); j < height; j++ {
+			lines = append(lines, "")
+		}
+	}
+	return lines
+}
+
+// the names of the player's inventory items followed by their neutral item,
+// skipping empty slots
+func (p DotaMatchPlayerDetails) itemNames(cat *DotaCatalog) []string {
+	var names []string
+	for _, id := range []int{p.Item0, p.Item1, p.Item2, p.Item3, p.Item4, p.Item5, p.ItemNeutral} {
+		if id != 0 {
+			names = append(names, cat.ItemName(id))
+		}
+	}
+	return names
+}
+
+// joins words with commas into lines no wider than max, each starting with
+// indent. A single word wider than max gets a line of its own.
+func wrap(words []string, indent string, max int) []string {
+	if len(words) == 0 {
+		return []string{indent + "-"}
+	}
+	var lines []string
+	line := indent + words[0]
+	for _, word := range words[1:] {
+		if utf8.RuneCountInString(line)+len(", ")+utf8.RuneCountInString(word) > max {
+			lines = append(lines, line+",")
+			line = indent + word
+			continue
+		}
+		line += ", " + word
+	}
+	return append(lines, line)
+}
+
+func width(lines []string) int {
+	w := 0
+	for _, line := range lines {
+		if n := utf8.RuneCountInString(line); n > w {
+			w = n
+		}
+	}
+	return w
+}
+
+func pad(s string, w int) string {
+	if n := utf8.RuneCountInString(s); n < w {
+		return s + strings.Repeat(" ", w-n)
+	}
+	return s
+}
+
+func center(s string, w int) string {
+	n := utf8.RuneCountInString(s)
+	if n >= w {
+		return s
+	}
+	return strings.Repeat(" ", (w-n)/2) + s
+}
+
+// formats a count in thousands once it reaches four digits, e.g. 23.4k
+func formatCount(n int) string {
+	if n < 1000 && n > -1000 {
+		return fmt.Sprint(n)
+	}
+	return fmt.Sprintf("%.1fk", float64(n)/1000)
+}
@@ -0,0 +1,80 @@
+package steam
+
+import (
+	"bytes"
+	"flag"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+var update = flag.Bool("update", false, "rewrite golden files with the current output")
+
+func TestScoreboard(t *testing.T) {
+	cat := NewDotaCatalog()
+	cat.AddHeroes(
+		DotaHero{Id: 1, Name: "npc_dota_hero_antimage", LocalizedName: "Anti-Mage"},
+		DotaHero{Id: 5, Name: "npc_dota_hero_crystal_maiden", LocalizedName: "Crystal Maiden"},
+		DotaHero{Id: 14, Name: "npc_dota_hero_pudge", LocalizedName: "Pudge"},
+		DotaHero{Id: 86, Name: "npc_dota_hero_rubick", LocalizedName: "Rubick"},
+	)
+	cat.AddItems(
+		DotaItem{Id: 1, Name: "item_blink", LocalizedName: "Blink Dagger"},
+		DotaItem{Id: 36, Name: "item_magic_wand", LocalizedName: "Magic Wand"},
+		DotaItem{Id: 48, Name: "item_travel_boots", LocalizedName: "Boots of Travel"},
+		DotaItem{Id: 63, Name: "item_power_treads", LocalizedName: "Power Treads"},
+		DotaItem{Id: 108, Name: "item_ultimate_scepter", LocalizedName: "Aghanim's Scepter"},
+		DotaItem{Id: 145, Name: "item_manta", LocalizedName: "Manta Style"},
+		DotaItem{Id: 156, Name: "item_abyssal_blade", LocalizedName: "Abyssal Blade"},
+		DotaItem{Id: 254, Name: "item_glimmer_cape", LocalizedName: "Glimmer Cape"},
+		DotaItem{Id: 1021, Name: "item_river_painter", LocalizedName: "River Vial: Chrome"},
+	)
+
+	d := DotaMatchDetails{
+		Id:             7000000001,
+		GameMode:       GameModeAllPick,
+		LobbyType:      LobbyTypeRanked,
+		Duration:       2537,
+		FirstBloodTime: 95,
+		RadiantName:    "Team Ice",
+		RadiantScore:   31,
+		DireScore:      44,
+		Players: []DotaMatchPlayerDetails{
+			// a full inventory that wraps onto a second line
+			{PlayerSlot: 0, HeroId: 1, Level: 25, Kills: 12, Deaths: 3, Assists: 4,
+				LastHits: 512, Denies: 14, GoldPerMinute: 702, XPPerMinute: 810,
+				NetWorth: 31250, HeroDamage: 24870,
+				Item0: 48, Item1: 63, Item2: 145, Item3: 156, Item4: 108, Item5: 36, ItemNeutral: 1021},
+			{PlayerSlot: 1, HeroId: 5, Level: 14, Kills: 1, Deaths: 11, Assists: 17,
+				LastHits: 40, Denies: 2, GoldPerMinute: 251, XPPerMinute: 330,
+				NetWorth: 7480, HeroDamage: 9120,
+				Item0: 254, Item1: 36},
+			{PlayerSlot: 128, HeroId: 14, Level: 22, Kills: 18, Deaths: 6, Assists: 9,
+				LastHits: 160, Denies: 8, GoldPerMinute: 480, XPPerMinute: 590,
+				NetWorth: 18300, HeroDamage: 30410,
+				Item0: 1, Item1: 108},
+			// left early, with an empty inventory
+			{PlayerSlot: 129, HeroId: 86, Level: 6, Kills: 0, Deaths: 2, Assists: 1,
+				LastHits: 12, Denies: 0, GoldPerMinute: 150, XPPerMinute: 180,
+				NetWorth: 950, HeroDamage: 430,
+				LeaverStatus: LeaverStatusAbandoned},
+		},
+	}
+
+	var buf bytes.Buffer
+	DotaRenderer{Catalog: cat}.Scoreboard(&buf, d)
+
+	golden := filepath.Join("testdata", "scoreboard.golden")
+	if *update {
+		if err := os.WriteFile(golden, buf.Bytes(), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	want, err := os.ReadFile(golden)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := buf.String(); got != string(want) {
+		t.Errorf("scoreboard differs from %s:\n--- got\n%s--- want\n%s", golden, got, want)
+	}
+}
@@ -0,0 +1,108 @@
+package steam
+
+import (
+	"fmt"
+	"sort"
+)
+
+type DotaTeam int
+
+const (
+	Radiant DotaTeam = iota
+	Dire
+)
+
+func (t DotaTeam) String() string {
+	switch t {
+	case Radiant:
+		return "Radiant"
+	case Dire:
+		return "Dire"
+	default:
+		return fmt.Sprintf("DotaTeam(%d)", int(t))
+	}
+}
+
+// a PlayerSlot packs a player's team and position into a byte: the high bit is
+// set for the dire, and the low three bits are the position within the team,
+// 0 through 4
+type PlayerSlot uint8
+
+func (s PlayerSlot) Team() DotaTeam {
+	if s&0x80 != 0 {
+		return Dire
+	}
+	return Radiant
+}
+
+func (s PlayerSlot) IsDire() bool {
+	return s.Team() == Dire
+}
+
+// the player's position within their team, from 0 to 4
+func (s PlayerSlot) Position() int {
+	return int(s & 0x7)
+}
+
+func (p DotaMatchPlayer) Team() DotaTeam {
+	return p.PlayerSlot.Team()
+}
+
+func (p DotaMatchPlayerDetails) Team() DotaTeam {
+	return p.PlayerSlot.Team()
+}
+
+// reports whether the player was on the winning side of the match
+func (p DotaMatchPlayerDetails) IsWinner(d DotaMatchDetails) bool {
+	return p.Team() == d.Winner()
+}
+
+// the team that won the match
+func (d DotaMatchDetails) Winner() DotaTeam {
+	if d.RadiantWin {
+		return Radiant
+	}
+	return Dire
+}
+
+// the radiant players, in slot order
+func (d DotaMatch) RadiantPlayers() []DotaMatchPlayer {
+	return d.teamPlayers(Radiant)
+}
+
+// the dire players, in slot order
+func (d DotaMatch) DirePlayers() []DotaMatchPlayer {
+	return d.teamPlayers(Dire)
+}
+
+func (d DotaMatch) teamPlayers(t DotaTeam) []DotaMatchPlayer {
+	var team []DotaMatchPlayer
+	for _, p := range d.Players {
+		if p.Team() == t {
+			team = append(team, p)
+		}
+	}
+	sort.SliceStable(team, func(i, j int) bool { return team[i].PlayerSlot < team[j].PlayerSlot })
+	return team
+}
+
+// the radiant players, in slot order
+func (d DotaMatchDetails) RadiantPlayers() []DotaMatchPlayerDetails {
+	return d.teamPlayers(Radiant)
+}
+
+// the dire players, in slot order
+func (d DotaMatchDetails) DirePlayers() []DotaMatchPlayerDetails {
+	return d.teamPlayers(Dire)
+}
+
+func (d DotaMatchDetails) teamPlayers(t DotaTeam) []DotaMatchPlayerDetails {
+	var team []DotaMatchPlayerDetails
+	for _, p := range d.Players {
+		if p.Team() == t {
+			team = append(team, p)
+		}
+	}
+	sort.SliceStable(team, func(i, j int) bool { return team[i].PlayerSlot < team[j].PlayerSlot })
+	return team
+}
@@ -1,21 +1,127 @@
 package steam
 
 import (
+	"errors"
 	"fmt"
+	"net/http"
+	"strings"
+	"time"
 )
 
+// sentinel errors for the classes of failure that callers typically want to
+// handle specially. Errors returned by the client match these with errors.Is.
+var (
+	ErrUnauthorized   = errors.New("unauthorized")
+	ErrRateLimited    = errors.New("rate limited")
+	ErrNotFound       = errors.New("not found")
+	ErrPrivateProfile = errors.New("private profile")
+
+	// the response body was larger than the client's max response size
+	ErrResponseTooLarge = errors.New("response too large")
+
+	// the response body ended early
+	ErrTruncated = errors.New("truncated response")
+)
+
+// ClientError is the error type returned by every Client method. Use
+// errors.As to get at the details of a failed request.
 type ClientError struct {
+	// the api endpoint that was being called, if any
+	Interface string
+	Method    string
+	Version   string
+
+	// the http status code of the response, or zero if no response was
+	// received
+	StatusCode int
+
+	// the status and statusDetail fields that some endpoints (notably the dota
+	// ones) embed in their result objects
+	Status       int
+	StatusDetail string
+
+	// whether repeating the same request may succeed, and how long steam
+	// asked us to wait before doing so
+	Retryable  bool
+	RetryAfter time.Duration
+
+	kind   error
 	msg    string
 	parent error
 }
 
 func (c ClientError) Error() string {
-	if c.parent == nil {
-		return fmt.Sprintf("steam client error: %s", c.msg)
+	var buf strings.Builder
+	buf.WriteString("steam client error: ")
+	if c.Interface != "" {
+		fmt.Fprintf(&buf, "%s/%s/%s: ", c.Interface, c.Method, c.Version)
+	}
+	buf.WriteString(c.msg)
+	if c.StatusDetail != "" {
+		fmt.Fprintf(&buf, ": %s", c.StatusDetail)
 	}
-	return fmt.Sprintf("steam client error: %s: %v", c.msg, c.parent)
+	if c.parent != nil {
+		fmt.Fprintf(&buf, ": %v", c.parent)
+	}
+	return buf.String()
+}
+
+// returns the underlying cause of the error, if any. Errors caused by a
+// canceled or expired context unwrap to context.Canceled or
+// context.DeadlineExceeded.
+func (c ClientError) Unwrap() error {
+	return c.parent
+}
+
+// reports whether the error belongs to one of the sentinel error classes
+func (c ClientError) Is(target error) bool {
+	return c.kind != nil && c.kind == target
 }
 
 func errorf(parent error, msg string, args ...interface{}) error {
 	return ClientError{msg: fmt.Sprintf(msg, args...), parent: parent}
 }
+
+// an error that occured while calling the given endpoint
+func (e endpoint) errorf(parent error, msg string, args ...interface{}) ClientError {
+	return ClientError{
+		Interface: e.iface,
+		Method:    e.method,
+		Version:   e.version,
+		msg:       fmt.Sprintf(msg, args...),
+		parent:    parent,
+	}
+}
+
+// an error for a response with a non-200 status code
+func (e endpoint) statusError(res *http.Response) ClientError {
+	err := e.errorf(nil, "unexpected http status %s", res.Status)
+	err.StatusCode = res.StatusCode
+	switch res.StatusCode {
+	case http.StatusUnauthorized, http.StatusForbidden:
+		err.kind = ErrUnauthorized
+	case http.StatusNotFound:
+		err.kind = ErrNotFound
+	case http.StatusTooManyRequests:
+		err.kind = ErrRateLimited
+		err.Retryable = true
+		err.RetryAfter = retryAfter(res.Header)
+	case http.StatusInternalServerError, http.StatusBadGateway,
+		http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		err.Retryable = true
+		err.RetryAfter = retryAfter(res.Header)
+	}
+	return err
+}
+
+// an error for a result object whose status field indicates failure
+func (e endpoint) resultError(status int, detail string) ClientError {
+	err := e.errorf(nil, "api returned status %d", status)
+	err.Status = status
+	err.StatusDetail = detail
+	if e.iface == "IDOTA2Match_570" && status == 15 {
+		// "Cannot get match history for a user that hasn't allowed it"
+		err.kind = ErrPrivateProfile
+	}
+	return err
+}
@@ -0,0 +1,79 @@
+package steam
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+	"sync"
+)
+
+// the api adds fields over time. Types that embed an Extra map keep the
+// fields they don't know about, so that decoding and re-encoding a response
+// never silently drops data.
+
+// the json keys of a struct type's fields, cached by type
+var knownFields sync.Map
+
+func jsonKeys(t reflect.Type) map[string]bool {
+	if keys, ok := knownFields.Load(t); ok {
+		return keys.(map[string]bool)
+	}
+	keys := make(map[string]bool, t.NumField())
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		name, _, _ := strings.Cut(f.Tag.Get("json"), ",")
+		switch name {
+		case "-":
+			continue
+		case "":
+			name = f.Name
+		}
+		keys[name] = true
+	}
+	knownFields.Store(t, keys)
+	return keys
+}
+
+// decodes b into v, which must be a pointer to a struct without its own
+// UnmarshalJSON method, returning the fields of b that v has no place for
+func unmarshalExtra(b []byte, v interface{}) (map[string]json.RawMessage, error) {
+	if err := json.Unmarshal(b, v); err != nil {
+		return nil, err
+	}
+	var all map[string]json.RawMessage
+	if err := json.Unmarshal(b, &all); err != nil {
+		return nil, err
+	}
+	keys := jsonKeys(reflect.TypeOf(v).Elem())
+	var extra map[string]json.RawMessage
+	for k, raw := range all {
+		if keys[k] {
+			continue
+		}
+		if extra == nil {
+			extra = make(map[string]json.RawMessage)
+		}
+		extra[k] = raw
+	}
+	return extra, nil
+}
+
+// encodes v, which must be a struct without its own MarshalJSON method, with
+// the extra fields merged in. Known fields win over extra fields of the same
+// name.
+func marshalExtra(v interface{}, extra map[string]json.RawMessage) ([]byte, error) {
+	b, err := json.Marshal(v)
+	if err != nil || len(extra) == 0 {
+		return b, err
+	}
+	var all map[string]json.RawMessage
+	if err := json.Unmarshal(b, &all); err != nil {
+		return nil, err
+	}
+	for k, raw := range extra {
+		if _, ok := all[k]; !ok {
+			all[k] = raw
+		}
+	}
+	return json.Marshal(all)
+}
@@ -0,0 +1,46 @@
+package steam
+
+import (
+	"os"
+	"path/filepath"
+)
+
+// writes data to the named file by way of a temp file in the same directory,
+// so that readers see either the old contents or the new, never a partial
+// write. The data is synced before the rename and the directory after it, so
+// the file also survives a crash or power loss intact. perm is applied to the
+// new file, as with os.WriteFile.
+func writeFileAtomic(name string, data []byte, perm os.FileMode) error {
+	tmp, err := os.CreateTemp(filepath.Dir(name), ".tmp-")
+	if err != nil {
+		return err
+	}
+	_, err = tmp.Write(data)
+	if err == nil {
+		err = tmp.Sync()
+	}
+	if cerr := tmp.Close(); err == nil {
+		err = cerr
+	}
+	if err == nil {
+		err = os.Chmod(tmp.Name(), perm)
+	}
+	if err == nil {
+		err = os.Rename(tmp.Name(), name)
+	}
+	if err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+	return syncDir(filepath.Dir(name))
+}
+
+// flushes a directory's entries to disk, making renames within it durable
+func syncDir(dir string) error {
+	d, err := os.Open(dir)
+	if err != nil {
+		return err
+	}
+	defer d.Close()
+	return d.Sync()
+}
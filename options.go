@@ -0,0 +1,121 @@
+package steam
+
+import (
+	"net/http"
+	"strings"
+	"time"
+)
+
+const DefaultBaseURL = "https://api.steampowered.com"
+
+// an Option configures a Client at construction time
+type Option func(*Client)
+
+// sets the root url that every request is made against. Useful for pointing
+// the client at a proxy or a local stand-in such as an httptest.Server.
+func WithBaseURL(base string) Option {
+	return func(c *Client) {
+		c.base = strings.TrimRight(base, "/")
+	}
+}
+
+// sets the http client used to make requests. The client is copied, so
+// options such as WithTimeout and WithTransport never modify the original.
+func WithHTTPClient(hc *http.Client) Option {
+	return func(c *Client) {
+		if hc != nil {
+			cp := *hc
+			c.http = &cp
+		}
+	}
+}
+
+// sets the RoundTripper used to make requests
+func WithTransport(rt http.RoundTripper) Option {
+	return func(c *Client) {
+		c.transport = rt
+	}
+}
+
+// sets the User-Agent header sent with every request
+func WithUserAgent(ua string) Option {
+	return func(c *Client) {
+		c.userAgent = ua
+	}
+}
+
+// sets an overall time limit for each request, including reading the
+// response body
+func WithTimeout(d time.Duration) Option {
+	return func(c *Client) {
+		c.timeout = d
+	}
+}
+
+// sets the limiter that paces the client's requests. A nil limiter disables
+// rate limiting.
+func WithLimiter(l Limiter) Option {
+	return func(c *Client) {
+		c.limiter = l
+	}
+}
+
+// sets the number of calls the client may make per UTC day. A limit of zero or
+// less disables the budget.
+func WithDailyBudget(limit int) Option {
+	return func(c *Client) {
+		if limit > 0 {
+			c.budget = NewDailyBudget(limit)
+		} else {
+			c.budget = nil
+		}
+	}
+}
+
+// sets the policy used to retry failed requests
+func WithRetryPolicy(p RetryPolicy) Option {
+	return func(c *Client) {
+		c.retryPolicy = p
+	}
+}
+
+// sets the cache used to store responses. Which endpoints are cached, and for
+// how long, is determined by the client's cache policy.
+func WithCache(cache Cache) Option {
+	return func(c *Client) {
+		c.cache = cache
+	}
+}
+
+// sets the cache policy, replacing DefaultCachePolicy
+func WithCachePolicy(p CachePolicy) Option {
+	return func(c *Client) {
+		c.cachePolicy = p
+	}
+}
+
+// sets a function that is called with the details of every api call the
+// client makes. The library itself never writes to stdout or stderr; this is
+// the place to hook up logging.
+func WithTrace(fn TraceFunc) Option {
+	return func(c *Client) {
+		c.traceFn = fn
+	}
+}
+
+// sets the largest response body, in bytes, that the client will read
+func WithMaxResponseSize(n int64) Option {
+	return func(c *Client) {
+		if n > 0 {
+			c.maxResponse = n
+		}
+	}
+}
+
+// sets the root url of the dota2.com data feed, which the client uses for
+// data that the steam web api lacks
+func WithDotaDatafeedURL(base string) Option {
+	return func(c *Client) {
+		c.datafeed = strings.TrimRight(base, "/")
+	}
+}
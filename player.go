@@ -5,31 +5,41 @@ import (
 )
 
 type PlayerSummary struct {
-	SteamId        uint64 `json:"steamid,string"`
-	Visibility     int    `json:"communityvisibilitystate"`
-	ProfileState   int    `json:"profilestate"`
-	PersonaName    string `json:"personaname"`
-	LastLogOff     int64  `json:"lastlogoff"`
-	ProfileUrl     string `json:"profileurl"`
-	Avatar         string `json:"avatar"`
-	AvatarMedium   string `json:"avatarmedium"`
-	AvatarFull     string `json:"avatarfull"`
-	PersonaState   int    `json:"personastate"`
-	LocCountryCode string `json:"loccountrycode"`
-	LocStateCode   string `json:"locstatecode"`
-	LocCityID      int    `json:"loccityid"`
+	SteamId        SteamID   `json:"steamid"`
+	Visibility     int       `json:"communityvisibilitystate"`
+	ProfileState   int       `json:"profilestate"`
+	PersonaName    string    `json:"personaname"`
+	LastLogOff     Timestamp `json:"lastlogoff"`
+	ProfileUrl     string    `json:"profileurl"`
+	Avatar         string    `json:"avatar"`
+	AvatarMedium   string    `json:"avatarmedium"`
+	AvatarFull     string    `json:"avatarfull"`
+	PersonaState   int       `json:"personastate"`
+	LocCountryCode string    `json:"loccountrycode"`
+	LocStateCode   string    `json:"locstatecode"`
+	LocCityID      int       `json:"loccityid"`
 }
 
 func (p PlayerSummary) Oneline() string {
-	return fmt.Sprintf("%d\t%s\t%s", p.SteamId, p.PersonaName, p.ProfileUrl)
+	return p.OnelineLayout("")
+}
+
+// like Oneline, with the last logoff time formatted with the given layout
+func (p PlayerSummary) OnelineLayout(layout string) string {
+	return fmt.Sprintf("%d\t%s\t%s\t%s", p.SteamId, p.PersonaName, p.ProfileUrl, p.LastLogOff.Format(layout))
 }
 
 type PlayerFriend struct {
-	SteamId      uint64 `json:"steamid,string"`
-	Relationship string `json:"relationship"`
-	FriendSince  int    `json:"friend_since"`
+	SteamId      SteamID   `json:"steamid"`
+	Relationship string    `json:"relationship"`
+	FriendSince  Timestamp `json:"friend_since"`
 }
 
 func (p PlayerFriend) Oneline() string {
-	return fmt.Sprintf("%d\t%s\t%d", p.SteamId, p.Relationship, p.FriendSince)
+	return p.OnelineLayout("")
+}
+
+// like Oneline, with the friend since time formatted with the given layout
+func (p PlayerFriend) OnelineLayout(layout string) string {
+	return fmt.Sprintf("%d\t%s\t%s", p.SteamId, p.Relationship, p.FriendSince.Format(layout))
 }
@@ -0,0 +1,195 @@
+package steam
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// a Limiter paces the requests made by a Client. Every request, including
+// those made through Get, waits on the client's limiter before being sent.
+type Limiter interface {
+	// blocks until a request to the named api interface may proceed, or until
+	// the context is done, in which case the context's error is returned
+	Wait(ctx context.Context, iface string) error
+}
+
+// a sustained request rate along with the number of requests that may be
+// made in a burst
+type Rate struct {
+	PerSecond float64
+	Burst     int
+}
+
+var (
+	// the rate applied to api interfaces that have no override
+	DefaultRate = Rate{PerSecond: 4, Burst: 8}
+
+	// the rates applied to specific api interfaces by default. The dota match
+	// endpoints start throttling at roughly one request per second.
+	DefaultRateOverrides = map[string]Rate{
+		"IDOTA2Match_570": {PerSecond: 1, Burst: 1},
+	}
+
+	// steam allows 100,000 calls per api key per day
+	DefaultDailyBudget = 100000
+)
+
+// a TokenBucket is a Limiter with a default bucket and optional per-interface
+// buckets. An interface with an override draws only from its own bucket.
+type TokenBucket struct {
+	mu        sync.Mutex
+	def       *bucket
+	overrides map[string]*bucket
+}
+
+func NewTokenBucket(def Rate, overrides map[string]Rate) *TokenBucket {
+	t := &TokenBucket{
+		def:       newBucket(def),
+		overrides: make(map[string]*bucket, len(overrides)),
+	}
+	for iface, r := range overrides {
+		t.overrides[iface] = newBucket(r)
+	}
+	return t
+}
+
+func (t *TokenBucket) Wait(ctx context.Context, iface string) error {
+	t.mu.Lock()
+	b, ok := t.overrides[iface]
+	if !ok {
+		b = t.def
+	}
+	delay := b.reserve(time.Now())
+	t.mu.Unlock()
+
+	if delay <= 0 {
+		return ctx.Err()
+	}
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		t.mu.Lock()
+		b.tokens++
+		t.mu.Unlock()
+		return ctx.Err()
+	}
+}
+
+type bucket struct {
+	rate   float64
+	burst  float64
+	tokens float64
+	last   time.Time
+}
+
+func newBucket(r Rate) *bucket {
+	if r.Burst < 1 {
+		r.Burst = 1
+	}
+	return &bucket{rate: r.PerSecond, burst: float64(r.Burst), tokens: float64(r.Burst)}
+}
+
+// takes a token from the bucket, returning how long the caller has to wait
+// before the token is actually available. The token count goes negative when
+// callers are queued up.
+func (b *bucket) reserve(now time.Time) time.Duration {
+	if b.rate <= 0 {
+		return 0
+	}
+	if !b.last.IsZero() {
+		b.tokens += now.Sub(b.last).Seconds() * b.rate
+		if b.tokens > b.burst {
+			b.tokens = b.burst
+		}
+	}
+	b.last = now
+	b.tokens--
+	if b.tokens >= 0 {
+		return 0
+	}
+	return time.Duration(-b.tokens / b.rate * float64(time.Second))
+}
+
+// a DailyBudget counts the calls made in the current UTC day and refuses
+// calls once the limit is reached, instead of letting steam throttle the key
+type DailyBudget struct {
+	mu    sync.Mutex
+	limit int
+	used  int
+	day   time.Time
+}
+
+func NewDailyBudget(limit int) *DailyBudget {
+	return &DailyBudget{limit: limit}
+}
+
+// BudgetExhaustedError is returned, wrapped in a ClientError, for calls made
+// after the daily budget has run out
+type BudgetExhaustedError struct {
+	Limit int
+	Reset time.Time
+}
+
+func (e BudgetExhaustedError) Error() string {
+	return fmt.Sprintf("daily budget of %d calls exhausted until %s", e.Limit, e.Reset.Format(time.RFC3339))
+}
+
+// resets the counter if the day has rolled over. Must be called with b.mu
+// held.
+func (b *DailyBudget) roll(now time.Time) {
+	day := now.UTC().Truncate(24 * time.Hour)
+	if !day.Equal(b.day) {
+		b.day = day
+		b.used = 0
+	}
+}
+
+func (b *DailyBudget) take() error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.roll(time.Now())
+	if b.used >= b.limit {
+		return BudgetExhaustedError{Limit: b.limit, Reset: b.day.Add(24 * time.Hour)}
+	}
+	b.used++
+	return nil
+}
+
+// gives back a call that was taken but never made
+func (b *DailyBudget) refund() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.used > 0 {
+		b.used--
+	}
+}
+
+func (b *DailyBudget) Limit() int {
+	return b.limit
+}
+
+// the number of calls made so far today
+func (b *DailyBudget) Used() int {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.roll(time.Now())
+	return b.used
+}
+
+// the number of calls left today
+func (b *DailyBudget) Remaining() int {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.roll(time.Now())
+	return b.limit - b.used
+}
+
+// the time at which the budget next resets
+func (b *DailyBudget) Reset() time.Time {
+	return time.Now().UTC().Truncate(24 * time.Hour).Add(24 * time.Hour)
+}
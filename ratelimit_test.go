@@ -0,0 +1,139 @@
+package steam
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestBucketReserve(t *testing.T) {
+	b := newBucket(Rate{PerSecond: 2, Burst: 3})
+	now := time.Now()
+	for i := 0; i < 3; i++ {
+		if d := b.reserve(now); d != 0 {
+			t.Fatalf("burst request %d delayed %v", i, d)
+		}
+	}
+	// the bucket is empty, so the next two callers queue up half a second
+	// apart
+	if d := b.reserve(now); d != 500*time.Millisecond {
+		t.Errorf("first queued request delayed %v, want 500ms", d)
+	}
+	if d := b.reserve(now); d != time.Second {
+		t.Errorf("second queued request delayed %v, want 1s", d)
+	}
+
+	// tokens refill at the rate but never beyond the burst
+	b = newBucket(Rate{PerSecond: 2, Burst: 3})
+	b.reserve(now)
+	later := now.Add(time.Hour)
+	for i := 0; i < 3; i++ {
+		if d := b.reserve(later); d != 0 {
+			t.Fatalf("refilled request %d delayed %v", i, d)
+		}
+	}
+	if d := b.reserve(later); d == 0 {
+		t.Error("bucket refilled beyond its burst")
+	}
+}
+
+func TestTokenBucketOverrides(t *testing.T) {
+	tb := NewTokenBucket(Rate{PerSecond: 1000, Burst: 1000}, map[string]Rate{"slow": {PerSecond: 0.001, Burst: 1}})
+	ctx := context.Background()
+	if err := tb.Wait(ctx, "slow"); err != nil {
+		t.Fatal(err)
+	}
+
+	// the override is empty, but other interfaces don't draw from it
+	for i := 0; i < 10; i++ {
+		if err := tb.Wait(ctx, "fast"); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Millisecond)
+	defer cancel()
+	if err := tb.Wait(ctx, "slow"); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("waiting on an empty bucket: %v", err)
+	}
+	// the canceled caller gives back its token, leaving one caller's worth
+	// of debt from the reservation that was honored
+	if tokens := tb.overrides["slow"].tokens; tokens < -0.01 || tokens > 0.01 {
+		t.Errorf("slow bucket holds %f tokens after a canceled wait, want 0", tokens)
+	}
+}
+
+func TestDailyBudget(t *testing.T) {
+	b := NewDailyBudget(2)
+	for i := 0; i < 2; i++ {
+		if err := b.take(); err != nil {
+			t.Fatal(err)
+		}
+	}
+	err := b.take()
+	var exhausted BudgetExhaustedError
+	if !errors.As(err, &exhausted) {
+		t.Fatalf("take on an empty budget: %v", err)
+	}
+	tomorrow := time.Now().UTC().Truncate(24 * time.Hour).Add(24 * time.Hour)
+	if exhausted.Limit != 2 || !exhausted.Reset.Equal(tomorrow) {
+		t.Errorf("exhausted error %+v, want reset at %s", exhausted, tomorrow)
+	}
+	if b.Used() != 2 || b.Remaining() != 0 {
+		t.Errorf("used %d, remaining %d", b.Used(), b.Remaining())
+	}
+
+	b.refund()
+	if b.Remaining() != 1 {
+		t.Errorf("remaining %d after a refund, want 1", b.Remaining())
+	}
+
+	// the count starts over on a new UTC day
+	b.mu.Lock()
+	b.day = b.day.Add(-24 * time.Hour)
+	b.mu.Unlock()
+	if b.Used() != 0 || b.Remaining() != 2 {
+		t.Errorf("after rollover used %d, remaining %d", b.Used(), b.Remaining())
+	}
+}
+
+func TestClientBudget(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"response":{"players":[]}}`)
+	}))
+	defer srv.Close()
+	c := NewClient("k",
+		WithBaseURL(srv.URL),
+		WithDailyBudget(2),
+		WithLimiter(NewTokenBucket(Rate{PerSecond: 0.001, Burst: 1}, nil)),
+		WithRetryPolicy(RetryPolicy{MaxAttempts: 1}))
+
+	if _, err := c.GetPlayerSummaries(1); err != nil {
+		t.Fatal(err)
+	}
+
+	// a call canceled while waiting on the limiter is refunded
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if _, err := c.GetPlayerSummariesContext(ctx, 1); err == nil {
+		t.Fatal("call succeeded on an empty limiter")
+	}
+	if used := c.Budget().Used(); used != 1 {
+		t.Errorf("budget used %d, want 1", used)
+	}
+
+	// once the budget is spent calls fail without waiting on the limiter
+	c = NewClient("k", WithBaseURL(srv.URL), WithDailyBudget(1), WithRetryPolicy(RetryPolicy{MaxAttempts: 1}))
+	if _, err := c.GetPlayerSummaries(1); err != nil {
+		t.Fatal(err)
+	}
+	_, err := c.GetPlayerSummaries(1)
+	var exhausted BudgetExhaustedError
+	if !errors.Is(err, ErrRateLimited) || !errors.As(err, &exhausted) {
+		t.Errorf("call over budget: %v", err)
+	}
+}
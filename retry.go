@@ -0,0 +1,124 @@
+package steam
+
+import (
+	"context"
+	"errors"
+	"math/rand"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// a RetryPolicy describes how a Client repeats failed requests. Only errors
+// classified as retryable are retried: network failures, throttling, 5xx
+// responses and truncated or garbled response bodies. The client only makes
+// GET requests, all of which are idempotent.
+type RetryPolicy struct {
+	// the total number of attempts made for a single call, including the
+	// first. Values of one or less disable retries.
+	MaxAttempts int
+
+	// the delay before the first retry. Each subsequent retry doubles the
+	// delay, up to MaxDelay, and the actual delay is jittered to between half
+	// and all of that value. A Retry-After header sent by steam takes
+	// precedence when it asks for a longer wait.
+	BaseDelay time.Duration
+	MaxDelay  time.Duration
+
+	// if non-nil, called before sleeping ahead of each retry
+	OnRetry func(RetryAttempt)
+}
+
+// describes a failed attempt that is about to be retried
+type RetryAttempt struct {
+	Interface string
+	Method    string
+	Version   string
+
+	// the number of the attempt that failed, starting at 1
+	Attempt int
+	Err     error
+
+	// how long the client will wait before the next attempt
+	Delay time.Duration
+}
+
+var DefaultRetryPolicy = RetryPolicy{
+	MaxAttempts: 3,
+	BaseDelay:   500 * time.Millisecond,
+	MaxDelay:    30 * time.Second,
+}
+
+// reports whether err is a ClientError that may succeed if the request is
+// made again
+func IsRetryable(err error) bool {
+	var e ClientError
+	return errors.As(err, &e) && e.Retryable
+}
+
+// the delay before the retry that follows the given failed attempt
+func (p RetryPolicy) delay(attempt int, err error) time.Duration {
+	d := p.BaseDelay
+	for i := 1; i < attempt && (p.MaxDelay <= 0 || d < p.MaxDelay); i++ {
+		d *= 2
+	}
+	if p.MaxDelay > 0 && d > p.MaxDelay {
+		d = p.MaxDelay
+	}
+	if d > 0 {
+		d = d/2 + time.Duration(rand.Int63n(int64(d/2)+1))
+	}
+	var e ClientError
+	if errors.As(err, &e) && e.RetryAfter > d {
+		d = e.RetryAfter
+	}
+	return d
+}
+
+// calls fn until it succeeds, fails with an error that isn't retryable, or
+// the policy's attempts are used up
+func (c *Client) retry(ctx context.Context, ep endpoint, fn func() error) error {
+	p := c.retryPolicy
+	for attempt := 1; ; attempt++ {
+		err := fn()
+		if err == nil || attempt >= p.MaxAttempts || !IsRetryable(err) {
+			return err
+		}
+		delay := p.delay(attempt, err)
+		if p.OnRetry != nil {
+			p.OnRetry(RetryAttempt{
+				Interface: ep.iface,
+				Method:    ep.method,
+				Version:   ep.version,
+				Attempt:   attempt,
+				Err:       err,
+				Delay:     delay,
+			})
+		}
+		timer := time.NewTimer(delay)
+		select {
+		case <-timer.C:
+		case <-ctx.Done():
+			timer.Stop()
+			return ep.errorf(ctx.Err(), "request canceled")
+		}
+	}
+}
+
+// parses a Retry-After header, which is either a number of seconds or an
+// http date. Returns zero if the header is missing or malformed.
+func retryAfter(h http.Header) time.Duration {
+	v := h.Get("Retry-After")
+	if v == "" {
+		return 0
+	}
+	if secs, err := strconv.Atoi(v); err == nil && secs > 0 {
+		return time.Duration(secs) * time.Second
+	}
+	if t, err := http.ParseTime(v); err == nil {
+		if d := time.Until(t); d > 0 {
+			return d
+		}
+	}
+	return 0
+}
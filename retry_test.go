@@ -0,0 +1,171 @@
+package steam
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestRetryDelay(t *testing.T) {
+	p := RetryPolicy{BaseDelay: 100 * time.Millisecond, MaxDelay: time.Second}
+	nominal := []time.Duration{100, 200, 400, 800, 1000, 1000}
+	for i, n := range nominal {
+		n *= time.Millisecond
+		for j := 0; j < 100; j++ {
+			d := p.delay(i+1, nil)
+			if d < n/2 || d > n {
+				t.Fatalf("attempt %d delayed %v, want between %v and %v", i+1, d, n/2, n)
+			}
+		}
+	}
+
+	// Retry-After wins when it's longer, but never shortens the backoff
+	if d := p.delay(1, ClientError{RetryAfter: 5 * time.Second}); d != 5*time.Second {
+		t.Errorf("delay with Retry-After 5s = %v", d)
+	}
+	if d := p.delay(4, ClientError{RetryAfter: time.Millisecond}); d < 400*time.Millisecond {
+		t.Errorf("short Retry-After shortened the delay to %v", d)
+	}
+}
+
+func TestRetryAfterHeader(t *testing.T) {
+	tests := []struct {
+		v        string
+		min, max time.Duration
+	}{
+		{"", 0, 0},
+		{"3", 3 * time.Second, 3 * time.Second},
+		{"-1", 0, 0},
+		{"soon", 0, 0},
+		{time.Now().Add(time.Minute).UTC().Format(http.TimeFormat), 58 * time.Second, time.Minute},
+		{time.Now().Add(-time.Minute).UTC().Format(http.TimeFormat), 0, 0},
+	}
+	for _, test := range tests {
+		h := http.Header{}
+		if test.v != "" {
+			h.Set("Retry-After", test.v)
+		}
+		if d := retryAfter(h); d < test.min || d > test.max {
+			t.Errorf("Retry-After %q = %v, want between %v and %v", test.v, d, test.min, test.max)
+		}
+	}
+}
+
+// serves the given statuses in turn, then 200s
+func statusServer(statuses ...int) (*httptest.Server, *atomic.Int32) {
+	var calls atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n := int(calls.Add(1))
+		if n <= len(statuses) {
+			if statuses[n-1] == http.StatusTooManyRequests {
+				w.Header().Set("Retry-After", "1")
+			}
+			w.WriteHeader(statuses[n-1])
+			return
+		}
+		fmt.Fprint(w, `{"response":{"players":[]}}`)
+	}))
+	return srv, &calls
+}
+
+func TestRetry(t *testing.T) {
+	srv, calls := statusServer(503, 503)
+	defer srv.Close()
+	var attempts []RetryAttempt
+	c := NewClient("k", WithBaseURL(srv.URL), WithRetryPolicy(RetryPolicy{
+		MaxAttempts: 3,
+		BaseDelay:   time.Millisecond,
+		OnRetry:     func(a RetryAttempt) { attempts = append(attempts, a) },
+	}))
+	if _, err := c.GetPlayerSummaries(1); err != nil {
+		t.Fatal(err)
+	}
+	if calls.Load() != 3 {
+		t.Errorf("made %d requests, want 3", calls.Load())
+	}
+	if len(attempts) != 2 || attempts[0].Attempt != 1 || attempts[1].Attempt != 2 {
+		t.Fatalf("OnRetry saw %+v", attempts)
+	}
+	if a := attempts[0]; a.Interface != "ISteamUser" || a.Method != "GetPlayerSummaries" || !IsRetryable(a.Err) {
+		t.Errorf("attempt %+v", a)
+	}
+}
+
+func TestRetryHonorsRetryAfter(t *testing.T) {
+	srv, calls := statusServer(429)
+	defer srv.Close()
+	var attempts []RetryAttempt
+	c := NewClient("k", WithBaseURL(srv.URL), WithRetryPolicy(RetryPolicy{
+		MaxAttempts: 2,
+		BaseDelay:   time.Millisecond,
+		OnRetry:     func(a RetryAttempt) { attempts = append(attempts, a) },
+	}))
+	start := time.Now()
+	if _, err := c.GetPlayerSummaries(1); err != nil {
+		t.Fatal(err)
+	}
+	if elapsed := time.Since(start); elapsed < time.Second {
+		t.Errorf("retried after %v, before Retry-After", elapsed)
+	}
+	if calls.Load() != 2 || len(attempts) != 1 {
+		t.Fatalf("%d requests, attempts %+v", calls.Load(), attempts)
+	}
+	if attempts[0].Delay != time.Second || !errors.Is(attempts[0].Err, ErrRateLimited) {
+		t.Errorf("attempt %+v", attempts[0])
+	}
+}
+
+func TestRetryGivesUp(t *testing.T) {
+	// errors that aren't retryable are returned at once
+	srv, calls := statusServer(404, 404)
+	defer srv.Close()
+	retried := false
+	c := NewClient("k", WithBaseURL(srv.URL), WithRetryPolicy(RetryPolicy{
+		MaxAttempts: 3,
+		BaseDelay:   time.Millisecond,
+		OnRetry:     func(RetryAttempt) { retried = true },
+	}))
+	if _, err := c.GetPlayerSummaries(1); !errors.Is(err, ErrNotFound) || IsRetryable(err) {
+		t.Errorf("404: %v", err)
+	}
+	if calls.Load() != 1 || retried {
+		t.Errorf("retried a 404: %d requests", calls.Load())
+	}
+
+	// retryable errors are returned once the attempts run out
+	srv, calls = statusServer(503, 503, 503)
+	defer srv.Close()
+	c = NewClient("k", WithBaseURL(srv.URL), WithRetryPolicy(RetryPolicy{MaxAttempts: 2, BaseDelay: time.Millisecond}))
+	_, err := c.GetPlayerSummaries(1)
+	var e ClientError
+	if !errors.As(err, &e) || e.StatusCode != 503 || !e.Retryable {
+		t.Errorf("503s: %v", err)
+	}
+	if calls.Load() != 2 {
+		t.Errorf("made %d requests, want 2", calls.Load())
+	}
+}
+
+func TestRetryCanceled(t *testing.T) {
+	srv, calls := statusServer(429, 429, 429)
+	defer srv.Close()
+	c := NewClient("k", WithBaseURL(srv.URL), WithRetryPolicy(RetryPolicy{MaxAttempts: 3, BaseDelay: time.Millisecond}))
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	start := time.Now()
+	_, err := c.GetPlayerSummariesContext(ctx, 1)
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("canceled retry: %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > 500*time.Millisecond {
+		t.Errorf("cancellation took %v", elapsed)
+	}
+	if calls.Load() != 1 {
+		t.Errorf("made %d requests, want 1", calls.Load())
+	}
+}
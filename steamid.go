@@ -0,0 +1,274 @@
+package steam
+
+import (
+	"bytes"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// a SteamID identifies a steam account. Its 64-bit form packs together the
+// account's universe (8 bits), account type (4 bits), instance (20 bits) and
+// 32-bit account id.
+type SteamID uint64
+
+type Universe uint8
+
+const (
+	UniverseInvalid Universe = iota
+	UniversePublic
+	UniverseBeta
+	UniverseInternal
+	UniverseDev
+)
+
+type AccountType uint8
+
+const (
+	AccountTypeInvalid AccountType = iota
+	AccountTypeIndividual
+	AccountTypeMultiseat
+	AccountTypeGameServer
+	AccountTypeAnonGameServer
+	AccountTypePending
+	AccountTypeContentServer
+	AccountTypeClan
+	AccountTypeChat
+	AccountTypeConsoleUser
+	AccountTypeAnonUser
+)
+
+// the letters that identify account types in the steam3 id format
+var accountTypeLetters = map[AccountType]byte{
+	AccountTypeIndividual:     'U',
+	AccountTypeMultiseat:      'M',
+	AccountTypeGameServer:     'G',
+	AccountTypeAnonGameServer: 'A',
+	AccountTypePending:        'P',
+	AccountTypeContentServer:  'C',
+	AccountTypeClan:           'g',
+	AccountTypeChat:           'T',
+	AccountTypeAnonUser:       'a',
+}
+
+// the instance used by individual accounts
+const DesktopInstance = 1
+
+// instance flags carried by chat ids
+const (
+	chatInstanceClan  = 0x80000
+	chatInstanceLobby = 0x40000
+)
+
+// builds a SteamID from its parts
+func NewSteamID(u Universe, t AccountType, instance uint32, account AccountID) SteamID {
+	return SteamID(uint64(u)<<56 | uint64(t&0xf)<<52 | uint64(instance&0xfffff)<<32 | uint64(account))
+}
+
+func (id SteamID) Universe() Universe {
+	return Universe(id >> 56)
+}
+
+func (id SteamID) Type() AccountType {
+	return AccountType(id >> 52 & 0xf)
+}
+
+func (id SteamID) Instance() uint32 {
+	return uint32(id >> 32 & 0xfffff)
+}
+
+// the 32-bit account id, which is what the dota api calls an account_id
+func (id SteamID) AccountID() AccountID {
+	return AccountID(id)
+}
+
+func (id SteamID) IsValid() bool {
+	return id.Universe() != UniverseInvalid && id.Type() != AccountTypeInvalid && id.AccountID() != 0
+}
+
+// formats the id as a decimal SteamID64
+func (id SteamID) String() string {
+	return strconv.FormatUint(uint64(id), 10)
+}
+
+// formats the id in the legacy STEAM_X:Y:Z format, which only describes
+// individual accounts. Following convention the universe digit is always 0.
+func (id SteamID) Steam2() string {
+	a := uint32(id.AccountID())
+	return fmt.Sprintf("STEAM_0:%d:%d", a&1, a>>1)
+}
+
+// formats the id in the [T:U:A] format, adding the instance where it differs
+// from the one implied by the type letter. Invalid ids and account types with
+// no letter can't be written this way and are formatted as a SteamID64
+// instead, so that the result always parses back to the same id.
+func (id SteamID) Steam3() string {
+	t := id.Type()
+	letter, ok := accountTypeLetters[t]
+	if !ok || !id.IsValid() {
+		return id.String()
+	}
+	instance := id.Instance()
+	var implied uint32
+	switch {
+	case t == AccountTypeIndividual:
+		implied = DesktopInstance
+	case t == AccountTypeChat && instance&chatInstanceClan != 0:
+		letter, implied = 'c', chatInstanceClan
+	case t == AccountTypeChat && instance&chatInstanceLobby != 0:
+		letter, implied = 'L', chatInstanceLobby
+	}
+	if instance != implied || t == AccountTypeAnonGameServer || t == AccountTypeMultiseat {
+		return fmt.Sprintf("[%c:%d:%d:%d]", letter, id.Universe(), id.AccountID(), instance)
+	}
+	return fmt.Sprintf("[%c:%d:%d]", letter, id.Universe(), id.AccountID())
+}
+
+// parses a SteamID in any of its textual formats: a SteamID64, a steam2 id
+// (STEAM_0:1:123), a steam3 id ([U:1:246], with or without brackets), or a
+// bare 32-bit account id, which is taken to be an individual account in the
+// public universe. 0 parses as the zero SteamID, so that a zero id survives a
+// round trip through its string form.
+func ParseSteamID(s string) (SteamID, error) {
+	s = strings.TrimSpace(s)
+	switch {
+	case strings.HasPrefix(s, "STEAM_"):
+		return parseSteam2(s)
+	case strings.HasPrefix(s, "["), strings.Count(s, ":") >= 2:
+		return parseSteam3(s)
+	}
+	n, err := strconv.ParseUint(s, 10, 64)
+	if err != nil {
+		return 0, errorf(nil, "invalid steam id %q", s)
+	}
+	if n == 0 {
+		return 0, nil
+	}
+	if n <= 0xffffffff {
+		return AccountID(n).SteamID(), nil
+	}
+	id := SteamID(n)
+	if !id.IsValid() {
+		return 0, errorf(nil, "invalid steam id %q", s)
+	}
+	return id, nil
+}
+
+func parseSteam2(s string) (SteamID, error) {
+	parts := strings.Split(strings.TrimPrefix(s, "STEAM_"), ":")
+	if len(parts) != 3 {
+		return 0, errorf(nil, "invalid steam2 id %q", s)
+	}
+	u, err1 := strconv.ParseUint(parts[0], 10, 8)
+	y, err2 := strconv.ParseUint(parts[1], 10, 1)
+	z, err3 := strconv.ParseUint(parts[2], 10, 31)
+	if err1 != nil || err2 != nil || err3 != nil {
+		return 0, errorf(nil, "invalid steam2 id %q", s)
+	}
+	// games of the era wrote the public universe as 0
+	if u == 0 {
+		u = uint64(UniversePublic)
+	}
+	id := NewSteamID(Universe(u), AccountTypeIndividual, DesktopInstance, AccountID(z<<1|y))
+	if !id.IsValid() {
+		return 0, errorf(nil, "invalid steam2 id %q", s)
+	}
+	return id, nil
+}
+
+func parseSteam3(s string) (SteamID, error) {
+	inner := strings.TrimSuffix(strings.TrimPrefix(s, "["), "]")
+	parts := strings.Split(inner, ":")
+	if len(parts) < 3 || len(parts) > 4 || len(parts[0]) != 1 {
+		return 0, errorf(nil, "invalid steam3 id %q", s)
+	}
+	u, err1 := strconv.ParseUint(parts[1], 10, 8)
+	a, err2 := strconv.ParseUint(parts[2], 10, 32)
+	if err1 != nil || err2 != nil {
+		return 0, errorf(nil, "invalid steam3 id %q", s)
+	}
+
+	letter := parts[0][0]
+	var t AccountType
+	var instance uint32
+	switch letter {
+	case 'c':
+		t, instance = AccountTypeChat, chatInstanceClan
+	case 'L':
+		t, instance = AccountTypeChat, chatInstanceLobby
+	case 'U':
+		t, instance = AccountTypeIndividual, DesktopInstance
+	default:
+		found := false
+		for typ, l := range accountTypeLetters {
+			if l == letter {
+				t, found = typ, true
+				break
+			}
+		}
+		if !found {
+			return 0, errorf(nil, "invalid steam3 id %q: unknown account type %c", s, letter)
+		}
+	}
+	if len(parts) == 4 {
+		i, err := strconv.ParseUint(parts[3], 10, 20)
+		if err != nil {
+			return 0, errorf(nil, "invalid steam3 id %q", s)
+		}
+		instance = uint32(i)
+	}
+	id := NewSteamID(Universe(u), t, instance, AccountID(a))
+	if !id.IsValid() {
+		return 0, errorf(nil, "invalid steam3 id %q", s)
+	}
+	return id, nil
+}
+
+// SteamIDs are written to json as strings, the way the steam api does, since
+// they don't fit in a javascript number
+func (id SteamID) MarshalJSON() ([]byte, error) {
+	return []byte(`"` + id.String() + `"`), nil
+}
+
+// accepts either a json string, in any format understood by ParseSteamID, or
+// a json number
+func (id *SteamID) UnmarshalJSON(b []byte) error {
+	if bytes.Equal(b, []byte("null")) {
+		return nil
+	}
+	s := string(b)
+	if len(b) > 1 && b[0] == '"' {
+		var err error
+		s, err = strconv.Unquote(s)
+		if err != nil {
+			return err
+		}
+		if s == "" {
+			*id = 0
+			return nil
+		}
+	}
+	parsed, err := ParseSteamID(s)
+	if err != nil {
+		return err
+	}
+	*id = parsed
+	return nil
+}
+
+// an AccountID is the 32-bit account number at the bottom of a SteamID. The
+// dota api identifies players by their account id.
+type AccountID uint32
+
+// the account id dota reports for players that hide their match data
+const AnonymousAccountID AccountID = 0xffffffff
+
+// the SteamID of the individual account in the public universe with this
+// account id
+func (a AccountID) SteamID() SteamID {
+	return NewSteamID(UniversePublic, AccountTypeIndividual, DesktopInstance, a)
+}
+
+func (a AccountID) IsAnonymous() bool {
+	return a == AnonymousAccountID
+}
@@ -0,0 +1,126 @@
+package steam
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestParseSteamID(t *testing.T) {
+	const gabe = SteamID(76561197960287930)
+	tests := []struct {
+		in   string
+		want SteamID
+	}{
+		{"76561197960287930", gabe},
+		{" 76561197960287930 ", gabe},
+		{"STEAM_0:0:11101", gabe},
+		{"STEAM_1:0:11101", gabe},
+		{"[U:1:22202]", gabe},
+		{"U:1:22202", gabe},
+		{"22202", gabe},
+		{"0", 0},
+	}
+	for _, test := range tests {
+		got, err := ParseSteamID(test.in)
+		if err != nil {
+			t.Errorf("ParseSteamID(%q) failed: %v", test.in, err)
+			continue
+		}
+		if got != test.want {
+			t.Errorf("ParseSteamID(%q) = %d, want %d", test.in, got, test.want)
+		}
+	}
+}
+
+func TestParseSteamIDInvalid(t *testing.T) {
+	for _, in := range []string{"", "bob", "STEAM_0:2:1", "STEAM_0:0", "[Q:1:2]", "[U:1]", "-1", "4503603922337793",
+		"[U:1:0]", "[I:0:0]", "[U:0:22202]", "STEAM_0:0:0"} {
+		if id, err := ParseSteamID(in); err == nil {
+			t.Errorf("ParseSteamID(%q) = %d, want an error", in, id)
+		}
+	}
+}
+
+func TestSteamIDFormats(t *testing.T) {
+	id := SteamID(76561197960287930)
+	if s := id.Steam2(); s != "STEAM_0:0:11101" {
+		t.Errorf("Steam2() = %q", s)
+	}
+	if s := id.Steam3(); s != "[U:1:22202]" {
+		t.Errorf("Steam3() = %q", s)
+	}
+	if a := id.AccountID(); a != 22202 {
+		t.Errorf("AccountID() = %d", a)
+	}
+	if back := AccountID(22202).SteamID(); back != id {
+		t.Errorf("AccountID(22202).SteamID() = %d", back)
+	}
+}
+
+func TestSteam3RoundTrip(t *testing.T) {
+	ids := []SteamID{
+		76561197960287930,
+		NewSteamID(UniversePublic, AccountTypeIndividual, 0, 22202),
+		NewSteamID(UniversePublic, AccountTypeMultiseat, 1, 5),
+		NewSteamID(UniversePublic, AccountTypeGameServer, 0, 5),
+		NewSteamID(UniversePublic, AccountTypeAnonGameServer, 1234, 5),
+		NewSteamID(UniversePublic, AccountTypeClan, 0, 5),
+		NewSteamID(UniverseBeta, AccountTypeChat, 0, 5),
+		NewSteamID(UniversePublic, AccountTypeChat, chatInstanceClan, 5),
+		NewSteamID(UniversePublic, AccountTypeChat, chatInstanceLobby, 5),
+		NewSteamID(UniversePublic, AccountTypeChat, chatInstanceLobby|1, 5),
+		NewSteamID(UniversePublic, AccountTypeConsoleUser, 0, 5),
+		NewSteamID(UniversePublic, AccountTypeAnonUser, 0, 5),
+		NewSteamID(UniversePublic, 14, 0, 5),
+		0,
+	}
+	for _, id := range ids {
+		s := id.Steam3()
+		back, err := ParseSteamID(s)
+		if err != nil {
+			t.Errorf("%d formatted as %q, which fails to parse: %v", id, s, err)
+			continue
+		}
+		if back != id {
+			t.Errorf("%d formatted as %q, which parses as %d", id, s, back)
+		}
+	}
+}
+
+func TestSteamIDJSON(t *testing.T) {
+	tests := []struct {
+		in   string
+		want SteamID
+	}{
+		{`"76561197960287930"`, 76561197960287930},
+		{`76561197960287930`, 76561197960287930},
+		{`"0"`, 0},
+		{`0`, 0},
+		{`""`, 0},
+		{`null`, 0},
+	}
+	for _, test := range tests {
+		var id SteamID
+		if err := json.Unmarshal([]byte(test.in), &id); err != nil {
+			t.Errorf("unmarshal %s failed: %v", test.in, err)
+			continue
+		}
+		if id != test.want {
+			t.Errorf("unmarshal %s = %d, want %d", test.in, id, test.want)
+		}
+	}
+
+	for _, id := range []SteamID{0, 76561197960287930} {
+		b, err := json.Marshal(id)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var back SteamID
+		if err := json.Unmarshal(b, &back); err != nil {
+			t.Fatal(err)
+		}
+		if back != id {
+			t.Errorf("%d came back from %s as %d", id, b, back)
+		}
+	}
+}
@@ -0,0 +1,62 @@
+package steam
+
+import (
+	"fmt"
+	"time"
+)
+
+// the layout Timestamp.String formats with
+const DefaultTimestampLayout = "2006-01-02 15:04:05 MST"
+
+// a point in time as steam reports it, in seconds since the unix epoch. Zero
+// means the time is unknown.
+type Timestamp int64
+
+func (t Timestamp) Time() time.Time {
+	if t == 0 {
+		return time.Time{}
+	}
+	return time.Unix(int64(t), 0)
+}
+
+func (t Timestamp) IsZero() bool {
+	return t == 0
+}
+
+// the time in the local time zone, formatted with DefaultTimestampLayout, or
+// "-" when the time is unknown
+func (t Timestamp) String() string {
+	return t.Format(DefaultTimestampLayout)
+}
+
+// the time in the local time zone, formatted with the given layout, or "-"
+// when the time is unknown. An empty layout means DefaultTimestampLayout.
+func (t Timestamp) Format(layout string) string {
+	if t == 0 {
+		return "-"
+	}
+	if layout == "" {
+		layout = DefaultTimestampLayout
+	}
+	return t.Time().Format(layout)
+}
+
+// a span of time in whole seconds, such as a match's duration or how far into
+// a match something happened. Times before the horn are negative.
+type Seconds int
+
+func (s Seconds) Duration() time.Duration {
+	return time.Duration(s) * time.Second
+}
+
+// formats the span as m:ss, or h:mm:ss for an hour or more
+func (s Seconds) String() string {
+	sign, n := "", int(s)
+	if n < 0 {
+		sign, n = "-", -n
+	}
+	if n >= 3600 {
+		return fmt.Sprintf("%s%d:%02d:%02d", sign, n/3600, n/60%60, n%60)
+	}
+	return fmt.Sprintf("%s%d:%02d", sign, n/60, n%60)
+}
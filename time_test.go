@@ -0,0 +1,69 @@
+package steam
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+)
+
+func TestTimestamp(t *testing.T) {
+	ts := Timestamp(1700000000)
+	if got := ts.Time().Unix(); got != 1700000000 {
+		t.Errorf("Time() = %d", got)
+	}
+	if got, want := ts.String(), time.Unix(1700000000, 0).Format(DefaultTimestampLayout); got != want {
+		t.Errorf("String() = %q, want %q", got, want)
+	}
+	if got, want := ts.Format(time.RFC3339), time.Unix(1700000000, 0).Format(time.RFC3339); got != want {
+		t.Errorf("Format(RFC3339) = %q, want %q", got, want)
+	}
+	if ts.Format("") != ts.String() {
+		t.Errorf("Format(\"\") = %q", ts.Format(""))
+	}
+
+	var zero Timestamp
+	if !zero.IsZero() || !zero.Time().IsZero() || zero.String() != "-" || zero.Format(time.RFC3339) != "-" {
+		t.Errorf("zero timestamp: %q", zero.String())
+	}
+}
+
+func TestSeconds(t *testing.T) {
+	tests := []struct {
+		in   Seconds
+		want string
+	}{
+		{0, "0:00"},
+		{83, "1:23"},
+		{-5, "-0:05"},
+		{2537, "42:17"},
+		{3725, "1:02:05"},
+	}
+	for _, test := range tests {
+		if got := test.in.String(); got != test.want {
+			t.Errorf("Seconds(%d).String() = %q, want %q", int(test.in), got, test.want)
+		}
+	}
+	if d := Seconds(95).Duration(); d != 95*time.Second {
+		t.Errorf("Duration() = %v", d)
+	}
+}
+
+func TestTimeFieldsJSON(t *testing.T) {
+	in := `{"start_time":1700000000,"duration":3725,"first_blood_time":83,"players":[{"ability_upgrades":[{"ability":5003,"time":95,"level":1}]}]}`
+	var d DotaMatchDetails
+	if err := json.Unmarshal([]byte(in), &d); err != nil {
+		t.Fatal(err)
+	}
+	if d.StartTime != 1700000000 || d.Duration != 3725 || d.FirstBloodTime != 83 || d.Players[0].AbilityUpgrades[0].Time != 95 {
+		t.Fatalf("decoded %+v", d)
+	}
+	b, err := json.Marshal(d)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var back map[string]interface{}
+	json.Unmarshal(b, &back)
+	if back["start_time"] != float64(1700000000) || back["duration"] != float64(3725) {
+		t.Errorf("encoded %s", b)
+	}
+}
@@ -0,0 +1,95 @@
+package steam
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// describes a single api call made by a Client, for logging and tracing.
+// Nothing in a RequestInfo ever contains the api key.
+type RequestInfo struct {
+	Interface string
+	Method    string
+	Version   string
+
+	// the request url, with the api key redacted. Empty for calls answered
+	// from the cache.
+	URL string
+
+	// true if the call was answered from the cache without making a request
+	Cached bool
+
+	// the http status code of the response, or zero if none was received
+	StatusCode int
+
+	// the time from sending the request to receiving the response headers
+	Duration time.Duration
+
+	Err error
+}
+
+// a TraceFunc is called once for every api call a Client makes, after the
+// call completes. It may be called concurrently.
+type TraceFunc func(RequestInfo)
+
+// a TraceFunc that writes each call to a structured logger. Failed calls are
+// logged at warn level, everything else at debug.
+func SlogTrace(l *slog.Logger) TraceFunc {
+	return func(info RequestInfo) {
+		level := slog.LevelDebug
+		attrs := []slog.Attr{
+			slog.String("interface", info.Interface),
+			slog.String("method", info.Method),
+			slog.String("version", info.Version),
+			slog.Bool("cached", info.Cached),
+		}
+		if !info.Cached {
+			attrs = append(attrs,
+				slog.String("url", info.URL),
+				slog.Int("status", info.StatusCode),
+				slog.Duration("duration", info.Duration),
+			)
+		}
+		if info.Err != nil {
+			level = slog.LevelWarn
+			attrs = append(attrs, slog.Any("error", info.Err))
+		}
+		l.LogAttrs(context.Background(), level, "steam api call", attrs...)
+	}
+}
+
+func (c *Client) trace(info RequestInfo) {
+	if c.traceFn != nil {
+		c.traceFn(info)
+	}
+}
+
+const redacted = "REDACTED"
+
+// replaces the api key in a url, if present
+func redactURL(u string) string {
+	i := strings.IndexByte(u, '?')
+	if i < 0 {
+		return u
+	}
+	q, err := url.ParseQuery(u[i+1:])
+	if err != nil || q.Get("key") == "" {
+		return u
+	}
+	q.Set("key", redacted)
+	return u[:i+1] + q.Encode()
+}
+
+// the errors produced by net/http carry the request url; strip the api key
+// out of them before they are handed to anybody
+func redactErr(err error) error {
+	var uerr *url.Error
+	if errors.As(err, &uerr) {
+		uerr.URL = redactURL(uerr.URL)
+	}
+	return err
+}
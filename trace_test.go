@@ -0,0 +1,105 @@
+package steam
+
+import (
+	"bytes"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+const sentinelKey = "S3NT1NEL-K3Y"
+
+// makes calls that succeed, fail with an http status, fail in the transport
+// and time out, checking that the api key appears in neither the traces nor
+// the errors
+func TestAPIKeyNeverLeaks(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("key") != sentinelKey {
+			t.Errorf("server got key %q", r.URL.Query().Get("key"))
+		}
+		switch r.URL.Query().Get("steamids") {
+		case "1":
+			fmt.Fprint(w, `{"response":{"players":[]}}`)
+		case "2":
+			w.WriteHeader(http.StatusInternalServerError)
+		case "3":
+			time.Sleep(200 * time.Millisecond)
+		}
+	}))
+	defer srv.Close()
+	closed := httptest.NewServer(http.NotFoundHandler())
+	closed.Close()
+
+	var (
+		mu     sync.Mutex
+		traces []RequestInfo
+		logs   bytes.Buffer
+	)
+	logger := slog.New(slog.NewTextHandler(&logs, &slog.HandlerOptions{Level: slog.LevelDebug}))
+	logTrace := SlogTrace(logger)
+	trace := func(info RequestInfo) {
+		mu.Lock()
+		defer mu.Unlock()
+		traces = append(traces, info)
+		logTrace(info)
+	}
+	opts := []Option{WithTrace(trace), WithRetryPolicy(RetryPolicy{MaxAttempts: 1}), WithTimeout(50 * time.Millisecond)}
+
+	c := NewClient(sentinelKey, append(opts, WithBaseURL(srv.URL))...)
+	var errs []error
+	for id := SteamID(1); id <= 3; id++ {
+		_, err := c.GetPlayerSummaries(id)
+		errs = append(errs, err)
+	}
+	c = NewClient(sentinelKey, append(opts, WithBaseURL(closed.URL))...)
+	_, err := c.GetPlayerSummaries(1)
+	errs = append(errs, err)
+
+	if errs[0] != nil {
+		t.Fatal(errs[0])
+	}
+	for i, err := range errs[1:] {
+		if err == nil {
+			t.Fatalf("call %d succeeded", i+2)
+		}
+		if strings.Contains(err.Error(), sentinelKey) {
+			t.Errorf("error leaks the key: %v", err)
+		}
+	}
+	if len(traces) != 4 {
+		t.Fatalf("traced %d calls, want 4", len(traces))
+	}
+	for _, info := range traces {
+		if strings.Contains(info.URL, sentinelKey) {
+			t.Errorf("traced url leaks the key: %s", info.URL)
+		}
+		if !strings.Contains(info.URL, "key="+redacted) {
+			t.Errorf("traced url lost its key param: %s", info.URL)
+		}
+		if info.Err != nil && strings.Contains(info.Err.Error(), sentinelKey) {
+			t.Errorf("traced error leaks the key: %v", info.Err)
+		}
+	}
+	if strings.Contains(logs.String(), sentinelKey) {
+		t.Errorf("log leaks the key:\n%s", logs.String())
+	}
+}
+
+func TestRedactURL(t *testing.T) {
+	tests := []struct{ in, want string }{
+		{"http://x/I/M/v1/?key=abc&steamid=1", "http://x/I/M/v1/?key=REDACTED&steamid=1"},
+		{"http://x/I/M/v1/?steamid=1", "http://x/I/M/v1/?steamid=1"},
+		{"http://x/I/M/v1/", "http://x/I/M/v1/"},
+		{"http://x/?key=a%2Bb%26c", "http://x/?key=REDACTED"},
+	}
+	for _, test := range tests {
+		if got := redactURL(test.in); got != test.want {
+			t.Errorf("redactURL(%q) = %q, want %q", test.in, got, test.want)
+		}
+	}
+}